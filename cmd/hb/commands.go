@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-hummingbird/hb"
+	"github.com/nstratos/go-hummingbird/hb/stats"
+	"golang.org/x/term"
+)
+
+func loginCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	username := fs.String("username", "", "Hummingbird `username`")
+	email := fs.String("email", "", "Hummingbird `email`, instead of username")
+	password := fs.String("password", "", "`password`, read from standard input if omitted")
+	return func(args []string) error {
+		if *username == "" && *email == "" {
+			return errors.New("login: -username or -email is required")
+		}
+		pass := *password
+		if pass == "" {
+			fmt.Fprint(e.stdout, "Password: ")
+			var err error
+			pass, err = readPassword(e.stdin)
+			if err != nil {
+				return fmt.Errorf("login: cannot read password: %v", err)
+			}
+			fmt.Fprintln(e.stdout)
+		}
+
+		token, _, err := e.client.User.AuthenticateContext(e.ctx, *username, *email, pass)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(e.config)
+		if err != nil {
+			return err
+		}
+		cfg.Username, cfg.Token = *username, token
+		if cfg.Username == "" {
+			cfg.Username = *email
+		}
+		if err := saveConfig(e.config, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintln(e.stdout, "Logged in, token stored in", e.config)
+		return nil
+	}
+}
+
+// readPassword reads a line with a password from r. If r is a terminal, the
+// password is not echoed.
+func readPassword(r io.Reader) (string, error) {
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		b, err := term.ReadPassword(int(f.Fd()))
+		return string(b), err
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func animeGetCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	lang := fs.String("lang", "", "title language `preference`: canonical, english or romanized")
+	return func(args []string) error {
+		id, err := oneArg(args, "anime ID or slug")
+		if err != nil {
+			return err
+		}
+		anime, _, err := e.client.Anime.GetContext(e.ctx, id, *lang)
+		if err != nil {
+			return err
+		}
+		return e.print(anime, func() table { return animeTable([]hb.Anime{*anime}) })
+	}
+}
+
+func animeSearchCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) == 0 {
+			return errors.New("expected a search query")
+		}
+		anime, _, err := e.client.Anime.SearchContext(e.ctx, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		return e.print(anime, func() table { return animeTable(anime) })
+	}
+}
+
+func animeTable(anime []hb.Anime) table {
+	t := table{header: []string{"ID", "SLUG", "TITLE", "TYPE", "EPISODES", "STATUS", "RATING"}}
+	for _, a := range anime {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(a.ID), a.Slug, a.Title, a.ShowType,
+			strconv.Itoa(a.EpisodeCount), a.Status,
+			strconv.FormatFloat(a.CommunityRating, 'f', 2, 64),
+		})
+	}
+	return t
+}
+
+func userGetCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		name, err := oneArg(args, "username")
+		if err != nil {
+			return err
+		}
+		u, _, err := e.client.User.GetContext(e.ctx, name)
+		if err != nil {
+			return err
+		}
+		return e.print(u, func() table {
+			return table{
+				header: []string{"NAME", "LOCATION", "KARMA", "LIFE SPENT ON ANIME", "ONLINE"},
+				rows: [][]string{{
+					u.Name, u.Location, strconv.Itoa(u.Karma),
+					strconv.Itoa(u.LifeSpentOnAnime), strconv.FormatBool(u.Online),
+				}},
+			}
+		})
+	}
+}
+
+func userFeedCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		name, err := oneArg(args, "username")
+		if err != nil {
+			return err
+		}
+		stories, _, err := e.client.User.FeedContext(e.ctx, name)
+		if err != nil {
+			return err
+		}
+		return e.print(stories, func() table {
+			t := table{header: []string{"ID", "TYPE", "UPDATED", "MEDIA", "ACTIVITY"}}
+			for _, s := range stories {
+				updated, media := "", ""
+				if s.UpdatedAt != nil {
+					updated = s.UpdatedAt.Format("2006-01-02 15:04")
+				}
+				if s.Media != nil {
+					media = s.Media.Title
+				} else if s.Manga != nil {
+					media = s.Manga.Title
+				}
+				var activity []string
+				for _, ss := range s.Substories {
+					activity = append(activity, substorySummary(ss))
+				}
+				t.rows = append(t.rows, []string{
+					strconv.Itoa(s.ID), string(s.StoryType), updated, media, strings.Join(activity, "; "),
+				})
+			}
+			return t
+		})
+	}
+}
+
+func substorySummary(ss hb.Substory) string {
+	if n, ok := ss.AsWatchedEpisode(); ok {
+		return "watched episode " + strconv.Itoa(n)
+	}
+	if status, ok := ss.AsStatusUpdate(); ok {
+		return "status " + status.String()
+	}
+	if user, ok := ss.AsFollowed(); ok {
+		return "followed " + user.Name
+	}
+	if comment, ok := ss.AsComment(); ok {
+		return comment
+	}
+	return string(ss.SubstoryType)
+}
+
+func userFavoritesCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		name, err := oneArg(args, "username")
+		if err != nil {
+			return err
+		}
+		anime, _, err := e.client.User.FavoriteAnimeContext(e.ctx, name)
+		if err != nil {
+			return err
+		}
+		return e.print(anime, func() table { return animeTable(anime) })
+	}
+}
+
+func libraryListCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	var status hb.LibraryStatus
+	fs.TextVar(&status, "status", status, "only list entries with this `status`")
+	return func(args []string) error {
+		name, err := oneArg(args, "username")
+		if err != nil {
+			return err
+		}
+		entries, _, err := e.client.User.LibraryContext(e.ctx, name, status)
+		if err != nil {
+			return err
+		}
+		return e.print(entries, func() table { return libraryTable(entries) })
+	}
+}
+
+func libraryTable(entries []hb.LibraryEntry) table {
+	t := table{header: []string{"ANIME", "TITLE", "STATUS", "EPISODES", "RATING", "REWATCHED"}}
+	for _, e := range entries {
+		slug, title, episodes := "", "", strconv.Itoa(e.EpisodesWatched)
+		if e.Anime != nil {
+			slug, title = e.Anime.Slug, e.Anime.Title
+			if e.Anime.EpisodeCount > 0 {
+				episodes += "/" + strconv.Itoa(e.Anime.EpisodeCount)
+			}
+		}
+		rating := ""
+		if e.Rating != nil {
+			rating = e.Rating.Value
+		}
+		t.rows = append(t.rows, []string{
+			slug, title, e.Status.String(), episodes, rating, strconv.Itoa(e.RewatchedTimes),
+		})
+	}
+	return t
+}
+
+func libraryUpdateCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	var entry hb.Entry
+	fs.TextVar(&entry.Status, "status", entry.Status, "library `status`")
+	fs.StringVar(&entry.Privacy, "privacy", "", "`privacy`: public or private")
+	fs.Func("rating", "`rating` from 0 to 5 in steps of 0.5 or negative, neutral or positive, 0 removes it", func(s string) error {
+		r, err := hb.ParseRating(s)
+		if err != nil {
+			return err
+		}
+		entry.SaneRatingUpdate = &r
+		return nil
+	})
+	rewatching := fs.Bool("rewatching", false, "mark the entry as being rewatched")
+	rewatched := fs.Int("rewatched", 0, "number of `times` rewatched")
+	notes := fs.String("notes", "", "personal `notes`, empty removes them")
+	episodes := fs.Int("episodes", 0, "number of `episodes` watched")
+	fs.BoolVar(&entry.IncrementEpisodes, "increment", false, "increment the episodes watched by one")
+	return func(args []string) error {
+		id, err := oneArg(args, "anime ID or slug")
+		if err != nil {
+			return err
+		}
+		// Only the flags that are set are sent, so that they can be set to
+		// zero values such as -episodes 0.
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "rewatching":
+				entry.Rewatching = rewatching
+			case "rewatched":
+				entry.RewatchedTimes = rewatched
+			case "notes":
+				entry.Notes = notes
+			case "episodes":
+				entry.EpisodesWatched = episodes
+			}
+		})
+		token, err := e.token()
+		if err != nil {
+			return err
+		}
+		var ep *hb.Entry
+		if entry != (hb.Entry{}) {
+			ep = &entry
+		}
+		le, _, err := e.client.Library.UpdateContext(e.ctx, id, token, ep)
+		if err != nil {
+			return err
+		}
+		return e.print(le, func() table { return libraryTable([]hb.LibraryEntry{*le}) })
+	}
+}
+
+func libraryRemoveCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		id, err := oneArg(args, "anime ID or slug")
+		if err != nil {
+			return err
+		}
+		token, err := e.token()
+		if err != nil {
+			return err
+		}
+		removed, _, err := e.client.Library.RemoveContext(e.ctx, id, token)
+		if err != nil {
+			return err
+		}
+		result := struct {
+			Anime   string `json:"anime"`
+			Removed bool   `json:"removed"`
+		}{id, removed}
+		return e.print(result, func() table {
+			return table{
+				header: []string{"ANIME", "REMOVED"},
+				rows:   [][]string{{id, strconv.FormatBool(removed)}},
+			}
+		})
+	}
+}
+
+func libraryStatsCmd(e *env, fs *flag.FlagSet) func([]string) error {
+	genres := fs.Bool("genres", false, "get the genres of each anime, which the library lacks, with one request per anime")
+	return func(args []string) error {
+		name, err := oneArg(args, "username")
+		if err != nil {
+			return err
+		}
+		entries, _, err := e.client.User.LibraryContext(e.ctx, name, "")
+		if err != nil {
+			return err
+		}
+		if *genres {
+			var ids []string
+			var withAnime []*hb.LibraryEntry
+			for i := range entries {
+				if entries[i].Anime != nil {
+					ids = append(ids, strconv.Itoa(entries[i].Anime.ID))
+					withAnime = append(withAnime, &entries[i])
+				}
+			}
+			anime, err := e.client.Anime.GetMany(e.ctx, ids, "")
+			if err != nil {
+				return err
+			}
+			for i, le := range withAnime {
+				le.Anime = anime[i]
+			}
+		}
+		s := stats.Compute(entries)
+		return e.print(s, func() table { return statsTable(s) })
+	}
+}
+
+func statsTable(s *stats.Stats) table {
+	t := table{header: []string{"STAT", "VALUE"}}
+	add := func(name, value string) { t.rows = append(t.rows, []string{name, value}) }
+	percent := func(f float64) string { return strconv.FormatFloat(f*100, 'f', 1, 64) + "%" }
+
+	add("entries", strconv.Itoa(s.Entries))
+	for _, status := range []hb.LibraryStatus{
+		hb.StatusCurrentlyWatching, hb.StatusPlanToWatch, hb.StatusCompleted, hb.StatusOnHold, hb.StatusDropped,
+	} {
+		add(status.String(), strconv.Itoa(s.Statuses[status]))
+	}
+	add("episodes watched", strconv.Itoa(s.EpisodesWatched))
+	add("time watched", s.WatchTime().String())
+	add("rewatches", strconv.Itoa(s.Rewatches))
+	add("ratings", strconv.Itoa(s.Ratings.Count))
+	add("mean rating", strconv.FormatFloat(s.Ratings.Mean, 'f', 2, 64))
+	add("completion rate", percent(s.CompletionRate))
+	add("drop rate", percent(s.DropRate))
+	for _, c := range s.ShowTypes {
+		add("show type "+c.Name, strconv.Itoa(c.Entries))
+	}
+	for _, c := range s.Genres {
+		add("genre "+c.Name, strconv.Itoa(c.Entries))
+	}
+	return t
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/nstratos/go-hummingbird/internal/atomicfile"
+)
+
+// config is the content of the config file.
+type config struct {
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+func defaultConfigPath() string {
+	if p := os.Getenv("HB_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".hb", "config.json")
+	}
+	return filepath.Join(dir, "hb", "config.json")
+}
+
+// loadConfig reads the config file at path. A missing file is an empty
+// config.
+func loadConfig(path string) (*config, error) {
+	cfg := new(config)
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// saveConfig writes cfg to the file at path which is only readable by the
+// user since it contains the token. The file is replaced atomically, so it
+// is never left partially written and an existing file that others could
+// read is replaced by one that they can't.
+func saveConfig(path string, cfg *config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(b, '\n'))
+}
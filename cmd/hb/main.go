@@ -0,0 +1,170 @@
+// Command hb is a command line client for the Hummingbird API.
+//
+// Usage:
+//
+//	hb <command> <subcommand> [flags] [arguments]
+//
+// The commands are:
+//
+//	login                       authenticate and store the token
+//	anime get <id>              show an anime by ID or slug
+//	anime search <query>        search anime by title
+//	user get <name>             show a user
+//	user feed <name>            show the activity feed of a user
+//	user favorites <name>       show the favorite anime of a user
+//	library list <name>         show the library of a user
+//	library update <anime>      add or update a library entry
+//	library remove <anime>      remove a library entry
+//	library stats <name>        show statistics of the library of a user
+//
+// Every subcommand accepts the -o flag which selects the output format and
+// can be one of "table" (the default), "json" or "yaml".
+//
+// The token obtained by login is stored in a config file which defaults to
+// hb/config.json inside the user's configuration directory and can be
+// changed with the HB_CONFIG environment variable or the -config flag.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "hb:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+const usage = `usage: hb <command> <subcommand> [flags] [arguments]
+
+commands:
+  login                    authenticate and store the token
+  anime get <id>           show an anime by ID or slug
+  anime search <query>     search anime by title
+  user get <name>          show a user
+  user feed <name>         show the activity feed of a user
+  user favorites <name>    show the favorite anime of a user
+  library list <name>      show the library of a user
+  library update <anime>   add or update a library entry
+  library remove <anime>   remove a library entry
+  library stats <name>     show statistics of the library of a user
+
+Run 'hb <command> <subcommand> -h' for the flags of a subcommand.
+`
+
+// env holds what the commands need to run.
+type env struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	format string
+	config string
+	client *hb.Client
+}
+
+// command is a subcommand that receives its flag set to register its own
+// flags and returns the function that runs it after the flags are parsed.
+type command func(e *env, fs *flag.FlagSet) func(args []string) error
+
+var commands = map[string]map[string]command{
+	"login": {"": loginCmd},
+	"anime": {
+		"get":    animeGetCmd,
+		"search": animeSearchCmd,
+	},
+	"user": {
+		"get":       userGetCmd,
+		"feed":      userFeedCmd,
+		"favorites": userFavoritesCmd,
+	},
+	"library": {
+		"list":   libraryListCmd,
+		"update": libraryUpdateCmd,
+		"remove": libraryRemoveCmd,
+		"stats":  libraryStatsCmd,
+	},
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+
+	subs, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	name, sub := args[0], ""
+	args = args[1:]
+	if _, ok := subs[""]; !ok {
+		if len(args) == 0 {
+			return fmt.Errorf("%s: missing subcommand", name)
+		}
+		sub, args = args[0], args[1:]
+		name += " " + sub
+	}
+	cmd, ok := subs[sub]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	e := &env{ctx: ctx, stdin: stdin, stdout: stdout}
+	fs := flag.NewFlagSet("hb "+name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.StringVar(&e.format, "o", "table", "output `format`: table, json or yaml")
+	fs.StringVar(&e.config, "config", defaultConfigPath(), "config `file` that stores the token")
+	baseURL := fs.String("base-url", os.Getenv("HB_BASE_URL"), "base `URL` of the API")
+	runCmd := cmd(e, fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch e.format {
+	case "table", "json", "yaml":
+	default:
+		return fmt.Errorf("unknown output format %q", e.format)
+	}
+
+	e.client = hb.NewClient(nil)
+	if *baseURL != "" {
+		u, err := url.Parse(strings.TrimSuffix(*baseURL, "/") + "/")
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %v", err)
+		}
+		e.client.BaseURL = u
+	}
+	return runCmd(fs.Args())
+}
+
+// token returns the token stored in the config file.
+func (e *env) token() (string, error) {
+	cfg, err := loadConfig(e.config)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Token == "" {
+		return "", errors.New("not logged in, run 'hb login' first")
+	}
+	return cfg.Token, nil
+}
+
+// oneArg checks that exactly one argument named name was given.
+func oneArg(args []string, name string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected one argument: %s", name)
+	}
+	return args[0], nil
+}
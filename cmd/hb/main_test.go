@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nstratos/go-hummingbird/hb"
+	"github.com/nstratos/go-hummingbird/hb/hbtest"
+	"github.com/nstratos/go-hummingbird/hb/stats"
+)
+
+// setup starts a fake API server and returns a function that runs the
+// command with the given arguments against it, along with the path of the
+// config file the command uses.
+func setup(t *testing.T) (*hbtest.Server, func(stdin string, args ...string) (string, error), string) {
+	srv := hbtest.NewServer()
+	srv.Seed(hbtest.DefaultFixtures())
+
+	dir, err := ioutil.TempDir("", "hb-cmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	})
+	config := filepath.Join(dir, "config.json")
+
+	runCmd := func(stdin string, args ...string) (string, error) {
+		var out bytes.Buffer
+		// The flags that point to the server go after the command words.
+		n := 2
+		if args[0] == "login" {
+			n = 1
+		}
+		args = append(args[:n:n], append([]string{"-base-url", srv.URL, "-config", config}, args[n:]...)...)
+		err := run(context.Background(), args, strings.NewReader(stdin), &out)
+		return out.String(), err
+	}
+	return srv, runCmd, config
+}
+
+func TestAnimeGet(t *testing.T) {
+	_, runCmd, _ := setup(t)
+
+	out, err := runCmd("", "anime", "get", "log-horizon")
+	if err != nil {
+		t.Fatalf("anime get returned error %v", err)
+	}
+	for _, want := range []string{"TITLE", "Log Horizon", "7622"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("anime get output %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestLibraryList_json(t *testing.T) {
+	_, runCmd, _ := setup(t)
+
+	out, err := runCmd("", "library", "list", "-status", "completed", "-o", "json", "cybrox")
+	if err != nil {
+		t.Fatalf("library list returned error %v", err)
+	}
+	var entries []hb.LibraryEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("library list output is not JSON: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Anime.Title != "Log Horizon" {
+		t.Errorf("library list returned %+v", entries)
+	}
+}
+
+func TestUserGet_yaml(t *testing.T) {
+	_, runCmd, _ := setup(t)
+
+	out, err := runCmd("", "user", "get", "-o", "yaml", "cybrox")
+	if err != nil {
+		t.Fatalf("user get returned error %v", err)
+	}
+	if want := "name: cybrox\n"; !strings.Contains(out, want) {
+		t.Errorf("user get output %q does not contain %q", out, want)
+	}
+}
+
+func TestLoginAndUpdate(t *testing.T) {
+	srv, runCmd, config := setup(t)
+
+	if _, err := runCmd("", "library", "remove", "nichijou"); err == nil {
+		t.Error("Expected not logged in error.")
+	}
+
+	if _, err := runCmd("password\n", "login", "-username", "cybrox"); err != nil {
+		t.Fatalf("login returned error %v", err)
+	}
+	cfg, err := loadConfig(config)
+	if err != nil || cfg.Token == "" {
+		t.Fatalf("config after login is %+v, %v, want a token", cfg, err)
+	}
+
+	if _, err := runCmd("", "library", "update", "-episodes", "20", "nichijou"); err != nil {
+		t.Fatalf("library update returned error %v", err)
+	}
+	if _, err := runCmd("", "library", "remove", "anohana"); err != nil {
+		t.Fatalf("library remove returned error %v", err)
+	}
+
+	entries := srv.Library("cybrox")
+	if got, want := len(entries), 4; got != want {
+		t.Errorf("library has %v entries, want %v", got, want)
+	}
+	for _, e := range entries {
+		if e.Anime.Slug == "nichijou" && e.EpisodesWatched != 20 {
+			t.Errorf("nichijou episodes watched is %v, want 20", e.EpisodesWatched)
+		}
+	}
+}
+
+func TestRun_unknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"manga", "get"}, nil, &out); err == nil {
+		t.Error("Expected unknown command error.")
+	}
+	if err := run(context.Background(), []string{"anime", "get", "-o", "xml", "x"}, nil, &out); err == nil {
+		t.Error("Expected unknown output format error.")
+	}
+}
+
+func TestLibraryStats_json(t *testing.T) {
+	_, runCmd, _ := setup(t)
+
+	out, err := runCmd("", "library", "stats", "-genres", "-o", "json", "cybrox")
+	if err != nil {
+		t.Fatalf("library stats returned error %v", err)
+	}
+	var s stats.Stats
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatalf("library stats output is not JSON: %v", err)
+	}
+	if s.Entries != 5 || s.Statuses[hb.StatusCompleted] != 1 || len(s.Genres) == 0 {
+		t.Errorf("library stats returned %+v", s)
+	}
+}
+
+func TestSaveConfig_mode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	// A config written by an older version that others can read.
+	if err := ioutil.WriteFile(path, []byte(`{"username":"cybrox"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveConfig(path, &config{Username: "cybrox", Token: "secret"}); err != nil {
+		t.Fatalf("saveConfig returned error %v", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fi.Mode().Perm(), os.FileMode(0600); got != want {
+		t.Errorf("config mode is %v, want %v", got, want)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil || cfg.Token != "secret" {
+		t.Errorf("loadConfig returned %+v, %v, want the saved token", cfg, err)
+	}
+}
+
+func TestReadPassword_piped(t *testing.T) {
+	got, err := readPassword(strings.NewReader("pass word\r\nrest"))
+	if err != nil || got != "pass word" {
+		t.Errorf("readPassword returned %q, %v, want %q, nil", got, err, "pass word")
+	}
+}
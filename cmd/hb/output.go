@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// table is the tabular representation of a command's result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// print writes v to the standard output in the selected format. The table is
+// only built if the format is "table".
+func (e *env) print(v interface{}, tbl func() table) error {
+	switch e.format {
+	case "json":
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.stdout, "%s\n", b)
+		return err
+	case "yaml":
+		return writeYAML(e.stdout, v)
+	default:
+		return tbl().write(e.stdout)
+	}
+}
+
+// writeYAML writes v as YAML. The value is first encoded to JSON so that the
+// YAML uses the same field names.
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	encodeYAML(&buf, doc, 0)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// encodeYAML writes v, a value decoded from JSON, as a YAML block at the
+// given indentation.
+func encodeYAML(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			buf.WriteString(pad + yamlScalar(k) + ":")
+			encodeYAMLValue(buf, v[k], indent+1)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok && len(m) > 0 {
+				// The first key of a map goes on the same line as the list
+				// marker, which takes the place of its indentation.
+				var item bytes.Buffer
+				encodeYAML(&item, m, indent+1)
+				buf.WriteString(pad + "- ")
+				buf.Write(item.Bytes()[len(pad)+2:])
+				continue
+			}
+			buf.WriteString(pad + "-")
+			encodeYAMLValue(buf, item, indent+1)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// encodeYAMLValue writes v after a key or list marker, either on the same line
+// if it is a scalar or an empty collection, or as a nested block.
+func encodeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		if len(vv) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(vv) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+		return
+	}
+	buf.WriteString("\n")
+	encodeYAML(buf, v, indent)
+}
+
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if yamlNeedsQuotes(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// yamlNeedsQuotes reports whether s would not be read back as the same string
+// if it was written without quotes.
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.ContainsAny(s, "\n\r\t\\")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	v := map[string]interface{}{
+		"name":   "cybrox",
+		"karma":  42,
+		"about":  "",
+		"bool":   "true",
+		"online": true,
+		"tags":   []string{"a: b", "plain"},
+		"nested": []map[string]interface{}{{"id": 1, "title": "Log Horizon"}},
+		"empty":  []int{},
+	}
+	want := `about: ""
+bool: "true"
+empty: []
+karma: 42
+name: cybrox
+nested:
+  - id: 1
+    title: Log Horizon
+online: true
+tags:
+  - "a: b"
+  - plain
+`
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, v); err != nil {
+		t.Fatalf("writeYAML returned error %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("writeYAML wrote\n%s\nwant\n%s", got, want)
+	}
+}
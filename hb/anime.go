@@ -1,8 +1,10 @@
 package hb
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // Anime represents a hummingbird anime object.
@@ -19,8 +21,8 @@ type Anime struct {
 	CoverImage      string  `json:"cover_image,omitempty"`
 	Synopsis        string  `json:"synopsis,omitempty"`
 	ShowType        string  `json:"show_type,omitempty"`
-	StartedAiring   string  `json:"started_airing,omitempty"`
-	FinishedAiring  string  `json:"finished_airing,omitempty"`
+	StartedAiring   *Date   `json:"started_airing,omitempty"`
+	FinishedAiring  *Date   `json:"finished_airing,omitempty"`
 	CommunityRating float64 `json:"community_rating,omitempty"`
 	AgeRating       string  `json:"age_rating,omitempty"`
 	Genres          []Genre `json:"genres,omitempty"`
@@ -28,6 +30,59 @@ type Anime struct {
 	FavRank         int     `json:"fav_rank,omitempty"` // When requesting user favorite anime.
 }
 
+// statusFinishedAiring is the status of an anime that has finished airing.
+const statusFinishedAiring = "Finished Airing"
+
+// airingEnd returns the last day that the anime aired or the zero time if it
+// is still airing. Anime that have finished airing without a finish date,
+// such as movies, only aired on the day they started.
+func (a *Anime) airingEnd() time.Time {
+	end := a.FinishedAiring.time()
+	if end.IsZero() && a.Status == statusFinishedAiring {
+		return a.StartedAiring.time()
+	}
+	return end
+}
+
+// AiringAt reports whether the anime was airing at t, which is from the day
+// it started airing until the day it finished airing, inclusive. Anime
+// without a start date are never airing and anime without a finish date are
+// airing from the day they started.
+func (a *Anime) AiringAt(t time.Time) bool {
+	start := a.StartedAiring.time()
+	if start.IsZero() || t.Before(start) {
+		return false
+	}
+	end := a.airingEnd()
+	return end.IsZero() || t.Before(end.AddDate(0, 0, 1))
+}
+
+// IsCurrentlyAiring reports whether the anime is airing now. See AiringAt.
+func (a *Anime) IsCurrentlyAiring() bool {
+	return a.AiringAt(time.Now())
+}
+
+// Season returns the season and the year that the anime started airing in.
+// The empty season is returned if the anime has no start date.
+func (a *Anime) Season() (Season, int) {
+	start := a.StartedAiring.time()
+	if start.IsZero() {
+		return "", 0
+	}
+	return SeasonOf(start)
+}
+
+// AiredIn reports whether the anime aired during any part of the season s of
+// year, including anime that started airing in an earlier season.
+func (a *Anime) AiredIn(s Season, year int) bool {
+	seasonStart, started := s.Start(year), a.StartedAiring.time()
+	if seasonStart.IsZero() || started.IsZero() || !started.Before(seasonStart.AddDate(0, 3, 0)) {
+		return false
+	}
+	end := a.airingEnd()
+	return end.IsZero() || !end.Before(seasonStart)
+}
+
 // Genre represents the genre of an anime.
 type Genre struct {
 	Name string
@@ -49,6 +104,11 @@ type AnimeService struct {
 //
 // Does not require authentication.
 func (s *AnimeService) Get(animeID, titleLangPref string) (*Anime, *http.Response, error) {
+	return s.GetContext(context.Background(), animeID, titleLangPref)
+}
+
+// GetContext is like Get but sends the request bound to ctx.
+func (s *AnimeService) GetContext(ctx context.Context, animeID, titleLangPref string) (*Anime, *http.Response, error) {
 	urlStr := fmt.Sprintf("api/v1/anime/%s", animeID)
 
 	req, err := s.client.NewRequest("GET", urlStr, nil)
@@ -63,7 +123,7 @@ func (s *AnimeService) Get(animeID, titleLangPref string) (*Anime, *http.Respons
 	}
 
 	anime := new(Anime)
-	resp, err := s.client.Do(req, anime)
+	resp, err := s.client.DoContext(withMethodName(ctx, "Anime.Get"), req, anime)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -75,6 +135,11 @@ func (s *AnimeService) Get(animeID, titleLangPref string) (*Anime, *http.Respons
 //
 // Does not require authentication.
 func (s *AnimeService) Search(query string) ([]Anime, *http.Response, error) {
+	return s.SearchContext(context.Background(), query)
+}
+
+// SearchContext is like Search but sends the request bound to ctx.
+func (s *AnimeService) SearchContext(ctx context.Context, query string) ([]Anime, *http.Response, error) {
 	const urlStr = "api/v1/search/anime"
 
 	req, err := s.client.NewRequest("GET", urlStr, nil)
@@ -87,7 +152,7 @@ func (s *AnimeService) Search(query string) ([]Anime, *http.Response, error) {
 	req.URL.RawQuery = v.Encode()
 
 	var anime []Anime
-	resp, err := s.client.Do(req, &anime)
+	resp, err := s.client.DoContext(withMethodName(ctx, "Anime.Search"), req, &anime)
 	if err != nil {
 		return nil, resp, err
 	}
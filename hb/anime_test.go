@@ -1,10 +1,12 @@
 package hb
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestAnimeService_Get(t *testing.T) {
@@ -104,3 +106,83 @@ func TestAnimeService_Search_httpError(t *testing.T) {
 		t.Error("Expected to return HTTP response despite the API error.")
 	}
 }
+
+func TestAnimeService_GetContext_deadlineExceeded(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	_, resp, err := client.Anime.GetContext(ctx, "log-horizon", "")
+	if got, want := err, context.DeadlineExceeded; got != want {
+		t.Errorf("Anime.GetContext returned error %v, want %v", got, want)
+	}
+
+	if resp != nil {
+		t.Error("Expected nil HTTP response when context deadline is exceeded.")
+	}
+}
+
+func TestAnime_AiringAt(t *testing.T) {
+	logHorizon := &Anime{StartedAiring: date(t, "2013-10-05"), FinishedAiring: date(t, "2014-03-22")}
+	ongoing := &Anime{StartedAiring: date(t, "2016-04-10")}
+	movie := &Anime{Status: "Finished Airing", StartedAiring: date(t, "2016-08-26")}
+	tests := []struct {
+		a    *Anime
+		t    string
+		want bool
+	}{
+		{logHorizon, "2013-10-04", false},
+		{logHorizon, "2013-10-05", true},
+		{logHorizon, "2014-03-22T23:00:00Z", true},
+		{logHorizon, "2014-03-23", false},
+		{ongoing, "2020-01-01", true},
+		{movie, "2016-08-26T20:00:00Z", true},
+		{movie, "2016-08-27", false},
+		{&Anime{}, "2016-01-01", false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.AiringAt(date(t, tt.t).Time); got != tt.want {
+			t.Errorf("AiringAt(%v) for %v - %v is %v, want %v", tt.t, tt.a.StartedAiring, tt.a.FinishedAiring, got, tt.want)
+		}
+	}
+}
+
+func TestAnime_Season(t *testing.T) {
+	a := &Anime{StartedAiring: date(t, "2013-10-05"), FinishedAiring: date(t, "2014-03-22")}
+	if season, year := a.Season(); season != Fall || year != 2013 {
+		t.Errorf("Season is %v %v, want %v 2013", season, year, Fall)
+	}
+	if season, _ := (&Anime{}).Season(); season != "" {
+		t.Errorf("Season without start date is %q, want empty", season)
+	}
+
+	tests := []struct {
+		s    Season
+		year int
+		want bool
+	}{
+		{Summer, 2013, false},
+		{Fall, 2013, true},
+		{Winter, 2014, true},
+		{Spring, 2014, false},
+	}
+	for _, tt := range tests {
+		if got := a.AiredIn(tt.s, tt.year); got != tt.want {
+			t.Errorf("AiredIn(%v, %v) is %v, want %v", tt.s, tt.year, got, tt.want)
+		}
+	}
+}
+
+func date(t *testing.T, s string) *Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &d
+}
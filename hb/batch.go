@@ -0,0 +1,147 @@
+package hb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultConcurrency is the concurrency of batch methods when
+// Client.Concurrency is not set.
+const defaultConcurrency = 4
+
+// BatchError is returned by batch methods, such as AnimeService.GetMany,
+// when some of the items failed. The results of the items that succeeded are
+// still returned.
+type BatchError struct {
+	// Errs has the error of each item, in the order of the input, and nil
+	// for the items that succeeded.
+	Errs []error
+}
+
+// Failed returns the number of items that failed.
+func (e *BatchError) Failed() int {
+	n := 0
+	for _, err := range e.Errs {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (e *BatchError) Error() string {
+	for _, err := range e.Errs {
+		if err != nil {
+			return fmt.Sprintf("hb: %d of %d items failed, first error: %v", e.Failed(), len(e.Errs), err)
+		}
+	}
+	return "hb: no items failed"
+}
+
+// Unwrap returns the errors of the items that failed, so that errors.Is and
+// errors.As match any of them.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (c *Client) concurrency() int {
+	if c.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return c.Concurrency
+}
+
+// fetchMany calls fetch once for each distinct key, with at most
+// c.Concurrency calls at the same time, and returns the results and the
+// errors in the order of keys. Duplicate keys share the result of the first
+// one. Once ctx is done, the keys that were not fetched yet fail with the
+// error of ctx. The returned error is a *BatchError if any key failed.
+func (c *Client) fetchMany(ctx context.Context, keys []string, fetch func(ctx context.Context, key string) (interface{}, error)) ([]interface{}, error) {
+	first := make(map[string]int, len(keys))
+	var unique []int
+	for i, k := range keys {
+		if _, ok := first[k]; !ok {
+			first[k] = i
+			unique = append(unique, i)
+		}
+	}
+
+	results := make([]interface{}, len(keys))
+	errs := make([]error, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.concurrency() && w < len(unique); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i], errs[i] = fetch(ctx, keys[i])
+			}
+		}()
+	}
+	for _, i := range unique {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	failed := false
+	for i, k := range keys {
+		j := first[k]
+		results[i], errs[i] = results[j], errs[j]
+		if errs[i] != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return results, &BatchError{Errs: errs}
+	}
+	return results, nil
+}
+
+// GetMany returns the anime with the given IDs or slugs, in the same order,
+// fetching them concurrently as described on Client.Concurrency. IDs that
+// appear more than once are only fetched once and share the same *Anime.
+// The rate limiter of the client, if any, applies to every request.
+//
+// If some anime can't be fetched, their result is nil and the error is a
+// *BatchError with the error of each ID. Once ctx is done, the anime that
+// were not fetched yet fail with the error of ctx.
+//
+// The titleLangPref is the same as in Get.
+func (s *AnimeService) GetMany(ctx context.Context, animeIDs []string, titleLangPref string) ([]*Anime, error) {
+	results, err := s.client.fetchMany(ctx, animeIDs, func(ctx context.Context, id string) (interface{}, error) {
+		a, _, err := s.GetContext(ctx, id, titleLangPref)
+		return a, err
+	})
+	anime := make([]*Anime, len(results))
+	for i, r := range results {
+		anime[i], _ = r.(*Anime)
+	}
+	return anime, err
+}
+
+// GetMany returns the users with the given names, in the same order,
+// fetching them concurrently the same way as AnimeService.GetMany.
+func (s *UserService) GetMany(ctx context.Context, usernames []string) ([]*User, error) {
+	results, err := s.client.fetchMany(ctx, usernames, func(ctx context.Context, name string) (interface{}, error) {
+		u, _, err := s.GetContext(ctx, name)
+		return u, err
+	})
+	users := make([]*User, len(results))
+	for i, r := range results {
+		users[i], _ = r.(*User)
+	}
+	return users, err
+}
@@ -0,0 +1,153 @@
+package hb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAnimeService_GetMany(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	inFlight, maxInFlight := 0, 0
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/anime/")
+		mu.Lock()
+		requests[id]++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		if id == "missing" {
+			http.Error(w, `{"error":"Couldn't find Anime with 'id'=missing"}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"slug":%q}`, id)
+	})
+
+	client.Concurrency = 2
+	ids := []string{"a", "b", "missing", "a", "c", "d"}
+	anime, err := client.Anime.GetMany(context.Background(), ids, "")
+
+	var berr *BatchError
+	if !errors.As(err, &berr) {
+		t.Fatalf("Anime.GetMany returned error %v, want *BatchError", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Anime.GetMany error %v does not match ErrNotFound", err)
+	}
+	if got, want := berr.Failed(), 1; got != want {
+		t.Errorf("Anime.GetMany failed %v items, want %v", got, want)
+	}
+	for i, id := range ids {
+		if id == "missing" {
+			if anime[i] != nil || berr.Errs[i] == nil {
+				t.Errorf("Anime.GetMany[%d] is %v, %v, want nil and an error", i, anime[i], berr.Errs[i])
+			}
+			continue
+		}
+		if anime[i] == nil || anime[i].Slug != id || berr.Errs[i] != nil {
+			t.Errorf("Anime.GetMany[%d] is %v, %v, want anime %q", i, anime[i], berr.Errs[i], id)
+		}
+	}
+	if anime[0] != anime[3] {
+		t.Error("Anime.GetMany returned different anime for the same ID")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["a"] != 1 {
+		t.Errorf("Anime.GetMany requested a duplicate ID %v times, want 1", requests["a"])
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Anime.GetMany sent %v requests at the same time, want at most 2", maxInFlight)
+	}
+}
+
+func TestUserService_GetMany(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name":%q}`, strings.TrimPrefix(r.URL.Path, "/api/v1/users/"))
+	})
+
+	names := []string{"cybrox", "josh", "vikhyat"}
+	users, err := client.User.GetMany(context.Background(), names)
+	if err != nil {
+		t.Fatalf("User.GetMany returned error %v", err)
+	}
+	for i, name := range names {
+		if users[i] == nil || users[i].Name != name {
+			t.Errorf("User.GetMany[%d] is %v, want user %q", i, users[i], name)
+		}
+	}
+}
+
+func TestAnimeService_GetMany_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	requests := 0
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		fmt.Fprint(w, `{}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	anime, err := client.Anime.GetMany(ctx, []string{"a", "b", "c"}, "")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Anime.GetMany returned error %v, want %v", err, context.Canceled)
+	}
+	for i, a := range anime {
+		if a != nil {
+			t.Errorf("Anime.GetMany[%d] is %v, want nil", i, a)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 0 {
+		t.Errorf("Anime.GetMany sent %v requests after cancellation, want 0", requests)
+	}
+}
+
+func TestAnimeService_GetMany_rateLimited(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	client.Concurrency = 4
+	client.RateLimiter = NewRateLimiter(50, 1)
+	if _, err := client.Anime.GetMany(context.Background(), []string{"a", "b", "c", "d"}, ""); err != nil {
+		t.Fatalf("Anime.GetMany returned error %v", err)
+	}
+	if got, want := client.RateLimiter.Stats().Requests, int64(4); got != want {
+		t.Errorf("Rate limiter saw %v requests, want %v", got, want)
+	}
+	if client.RateLimiter.Stats().Delayed == 0 {
+		t.Error("Anime.GetMany was not delayed by the rate limiter")
+	}
+}
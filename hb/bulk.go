@@ -0,0 +1,245 @@
+package hb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrAborted is the error of the changes of a bulk update that were not sent
+// because an earlier change failed and the update was being rolled back.
+var ErrAborted = errors.New("hb: bulk update aborted")
+
+// rollbackFields are the fields of a library entry that a rollback restores.
+var rollbackFields = []string{"status", "episodes_watched", "rating", "rewatched_times", "rewatching", "notes", "private"}
+
+// BulkChange is one change of a bulk library update.
+type BulkChange struct {
+	// AnimeID is the ID or slug of the anime of the library entry.
+	AnimeID string
+
+	// Entry holds the values to add or update, as in LibraryService.Update.
+	Entry Entry
+
+	// Previous is the library entry before the change, which a rollback
+	// restores.
+	Previous *LibraryEntry
+
+	// Added reports that the entry was not in the library before the
+	// change, so a rollback removes it. A change to be rolled back must
+	// have either a Previous entry or Added set.
+	Added bool
+}
+
+// NewBulkChange returns the change that updates the library entry le with
+// the values of entry and that restores le on rollback.
+func NewBulkChange(le LibraryEntry, entry Entry) BulkChange {
+	c := BulkChange{Entry: entry, Previous: &le}
+	if le.Anime != nil {
+		c.AnimeID = strconv.Itoa(le.Anime.ID)
+	}
+	return c
+}
+
+// NewBulkAdd returns the change that adds the anime with the given ID or
+// slug to the library with the values of entry and that removes it on
+// rollback.
+func NewBulkAdd(animeID string, entry Entry) BulkChange {
+	return BulkChange{AnimeID: animeID, Entry: entry, Added: true}
+}
+
+// BulkOptions configure a bulk library update.
+type BulkOptions struct {
+	// Concurrency is the maximum number of changes sent at the same time.
+	// Defaults to the Concurrency of the Client.
+	Concurrency int
+
+	// Progress, if set, is called after each change is sent. It is never
+	// called concurrently.
+	Progress func(BulkProgress)
+
+	// Rollback makes a failed change undo the changes that were already
+	// applied. See LibraryService.BulkUpdate.
+	Rollback bool
+}
+
+// BulkProgress reports the progress of a bulk update after each change.
+type BulkProgress struct {
+	// Done is the number of changes sent so far, including this one, out of
+	// Total.
+	Done, Total int
+
+	// Result is the result of the change that was sent.
+	Result BulkResult
+}
+
+// BulkResult is the result of one change of a bulk update.
+type BulkResult struct {
+	// Index is the index of the change in the changes of the update.
+	Index  int
+	Change BulkChange
+
+	// Entry is the updated library entry and Err the error of the change,
+	// if it failed.
+	Entry *LibraryEntry
+	Err   error
+
+	// RolledBack reports whether the change was applied and then rolled
+	// back, and RollbackErr is the error of rolling it back, if it failed.
+	RolledBack  bool
+	RollbackErr error
+}
+
+// BulkReport is the result of a bulk update.
+type BulkReport struct {
+	// Results are the results of the changes, in the same order.
+	Results []BulkResult
+
+	// RolledBack reports whether the update was rolled back.
+	RolledBack bool
+}
+
+// Succeeded returns the results of the changes that were applied and not
+// rolled back.
+func (r *BulkReport) Succeeded() []BulkResult {
+	var results []BulkResult
+	for _, res := range r.Results {
+		if res.Err == nil && !res.RolledBack {
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// Failed returns the results of the changes that failed.
+func (r *BulkReport) Failed() []BulkResult {
+	var results []BulkResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// BulkUpdate applies many changes to the library of the user that authToken
+// belongs to, sending them concurrently. The report has the result of each
+// change in the same order as changes. If any change fails, the error is a
+// *BatchError with the error of each change. Once ctx is done, the changes
+// that were not sent yet fail with the error of ctx.
+//
+// By default a change that fails doesn't affect the rest. With
+// opts.Rollback, no more changes are sent after a change fails; the ones not
+// sent fail with ErrAborted. Once the changes in flight finish, the ones that
+// were applied are rolled back, in reverse order, by restoring their
+// Previous entry or, for Added changes, removing the entry. Rollbacks are
+// sent even if ctx is done. With opts.Rollback, every change must have a
+// Previous entry or be Added, otherwise no change is sent and an error is
+// returned, so that a rollback never removes an entry it doesn't know about.
+//
+// The opts can be nil for the default options.
+func (s *LibraryService) BulkUpdate(ctx context.Context, authToken string, changes []BulkChange, opts *BulkOptions) (*BulkReport, error) {
+	if opts == nil {
+		opts = new(BulkOptions)
+	}
+	if opts.Rollback {
+		for _, c := range changes {
+			if c.Previous == nil && !c.Added {
+				return nil, fmt.Errorf("hb: cannot roll back the change of anime %q without a previous entry", c.AnimeID)
+			}
+		}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = s.client.concurrency()
+	}
+
+	report := &BulkReport{Results: make([]BulkResult, len(changes))}
+	var (
+		mu      sync.Mutex
+		done    int
+		failed  bool
+		applied []int
+	)
+	finish := func(res BulkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		report.Results[res.Index] = res
+		if res.Err != nil {
+			failed = true
+		} else {
+			applied = append(applied, res.Index)
+		}
+		done++
+		if opts.Progress != nil {
+			opts.Progress(BulkProgress{Done: done, Total: len(changes), Result: res})
+		}
+	}
+	aborted := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return opts.Rollback && failed
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(changes); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				res := BulkResult{Index: i, Change: changes[i]}
+				switch {
+				case ctx.Err() != nil:
+					res.Err = ctx.Err()
+				case aborted():
+					res.Err = ErrAborted
+				default:
+					entry := changes[i].Entry
+					res.Entry, _, res.Err = s.UpdateContext(ctx, changes[i].AnimeID, authToken, &entry)
+				}
+				finish(res)
+			}
+		}()
+	}
+	for i := range changes {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if opts.Rollback && failed {
+		report.RolledBack = true
+		ctx := context.WithoutCancel(ctx)
+		for j := len(applied) - 1; j >= 0; j-- {
+			res := &report.Results[applied[j]]
+			res.RollbackErr = s.rollback(ctx, authToken, res.Change)
+			res.RolledBack = res.RollbackErr == nil
+		}
+	}
+
+	if !failed {
+		return report, nil
+	}
+	errs := make([]error, len(changes))
+	for i, res := range report.Results {
+		errs[i] = res.Err
+	}
+	return report, &BatchError{Errs: errs}
+}
+
+// rollback restores the library entry that c changed.
+func (s *LibraryService) rollback(ctx context.Context, authToken string, c BulkChange) error {
+	if c.Added {
+		_, _, err := s.RemoveContext(ctx, c.AnimeID, authToken)
+		return err
+	}
+	entry, err := entryFromLibraryEntry(*c.Previous, rollbackFields)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.UpdateContext(ctx, c.AnimeID, authToken, entry)
+	return err
+}
@@ -0,0 +1,177 @@
+package hb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// bulkServer serves library updates and removals, failing the updates of the
+// anime "fail", and records the requests it receives as "METHOD path body".
+type bulkServer struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (b *bulkServer) serve(t *testing.T) {
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/libraries/")
+		b.mu.Lock()
+		b.requests = append(b.requests, path+" "+strings.TrimSpace(string(body)))
+		b.mu.Unlock()
+		switch {
+		case path == "fail":
+			http.Error(w, `{"error":"Invalid entry"}`, http.StatusUnprocessableEntity)
+		case strings.HasSuffix(path, "/remove"):
+			fmt.Fprint(w, "true")
+		default:
+			fmt.Fprintf(w, `{"id":1,"status":"currently-watching","anime":{"slug":%q}}`, path)
+		}
+	})
+}
+
+func (b *bulkServer) requestsFor(prefix string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var reqs []string
+	for _, r := range b.requests {
+		if strings.HasPrefix(r, prefix) {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+func TestLibraryService_BulkUpdate(t *testing.T) {
+	setup()
+	defer teardown()
+	srv := new(bulkServer)
+	srv.serve(t)
+
+	watching := Entry{Status: StatusCurrentlyWatching}
+	changes := []BulkChange{
+		{AnimeID: "a", Entry: watching},
+		{AnimeID: "fail", Entry: watching},
+		{AnimeID: "c", Entry: watching},
+	}
+	var progress []int
+	opts := &BulkOptions{Concurrency: 2, Progress: func(p BulkProgress) {
+		if p.Total != len(changes) {
+			t.Errorf("Progress total is %v, want %v", p.Total, len(changes))
+		}
+		progress = append(progress, p.Done)
+	}}
+
+	report, err := client.Library.BulkUpdate(context.Background(), "valid_user_token", changes, opts)
+	var berr *BatchError
+	if !errors.As(err, &berr) || berr.Failed() != 1 || berr.Errs[1] == nil {
+		t.Fatalf("Library.BulkUpdate returned error %v, want a *BatchError for change 1", err)
+	}
+	if got, want := fmt.Sprint(progress), "[1 2 3]"; got != want {
+		t.Errorf("Progress was called with %v, want %v", got, want)
+	}
+	for i, res := range report.Results {
+		if res.Index != i || res.Change.AnimeID != changes[i].AnimeID {
+			t.Errorf("Result %d is for change %d %q", i, res.Index, res.Change.AnimeID)
+		}
+	}
+	if got := report.Succeeded(); len(got) != 2 || got[0].Entry.Anime.Slug != "a" || got[1].Entry.Anime.Slug != "c" {
+		t.Errorf("Succeeded is %+v, want the changes of a and c", got)
+	}
+	if got := report.Failed(); len(got) != 1 || got[0].Index != 1 {
+		t.Errorf("Failed is %+v, want change 1", got)
+	}
+	if report.RolledBack {
+		t.Error("BulkUpdate rolled back without Rollback")
+	}
+}
+
+func TestLibraryService_BulkUpdate_rollbackWithoutPrevious(t *testing.T) {
+	setup()
+	defer teardown()
+	srv := new(bulkServer)
+	srv.serve(t)
+
+	changes := []BulkChange{NewBulkAdd("1", Entry{}), {AnimeID: "2"}}
+	report, err := client.Library.BulkUpdate(context.Background(), "valid_user_token", changes, &BulkOptions{Rollback: true})
+	if err == nil || report != nil {
+		t.Errorf("Library.BulkUpdate of a change without a previous entry returned %+v, %v, want an error", report, err)
+	}
+	if got := srv.requestsFor(""); len(got) != 0 {
+		t.Errorf("Library.BulkUpdate sent %q, want no requests", got)
+	}
+}
+
+func TestLibraryService_BulkUpdate_rollback(t *testing.T) {
+	setup()
+	defer teardown()
+	srv := new(bulkServer)
+	srv.serve(t)
+
+	watching := Entry{Status: StatusCurrentlyWatching}
+	changes := []BulkChange{
+		NewBulkChange(LibraryEntry{Anime: &Anime{ID: 1}, Status: StatusPlanToWatch, EpisodesWatched: 2}, watching),
+		NewBulkAdd("2", watching),
+		NewBulkAdd("fail", watching),
+		NewBulkAdd("4", watching),
+	}
+	opts := &BulkOptions{Concurrency: 1, Rollback: true}
+
+	report, err := client.Library.BulkUpdate(context.Background(), "valid_user_token", changes, opts)
+	if err == nil {
+		t.Fatal("Library.BulkUpdate returned no error")
+	}
+	if !report.RolledBack {
+		t.Error("BulkUpdate was not rolled back")
+	}
+	if res := report.Results[3]; res.Err != ErrAborted {
+		t.Errorf("Change after the failure has error %v, want %v", res.Err, ErrAborted)
+	}
+	for _, i := range []int{0, 1} {
+		if res := report.Results[i]; !res.RolledBack || res.RollbackErr != nil || res.Err != nil {
+			t.Errorf("Change %d is %+v, want rolled back", i, res)
+		}
+	}
+	if got := report.Succeeded(); len(got) != 0 {
+		t.Errorf("Succeeded is %+v after a rollback, want none", got)
+	}
+
+	// The entry with a previous state is restored and the new one removed.
+	want := `1 {"id":"1","auth_token":"valid_user_token","status":"plan-to-watch","privacy":"public","sane_rating_update":"0","rewatching":false,"rewatched_times":0,"notes":"","episodes_watched":2}`
+	if got := srv.requestsFor("1 "); len(got) != 2 || got[1] != want {
+		t.Errorf("Requests for anime 1 are %q, want the rollback %q last", got, want)
+	}
+	if got := srv.requestsFor("2/remove"); len(got) != 1 {
+		t.Errorf("Anime 2 was removed %d times, want 1", len(got))
+	}
+	if got := srv.requestsFor("4"); len(got) != 0 {
+		t.Errorf("Requests for anime 4 are %q, want none after the failure", got)
+	}
+}
+
+func TestLibraryService_BulkUpdate_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+	srv := new(bulkServer)
+	srv.serve(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	changes := []BulkChange{{AnimeID: "a"}, {AnimeID: "b"}}
+	report, err := client.Library.BulkUpdate(ctx, "valid_user_token", changes, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Library.BulkUpdate returned error %v, want %v", err, context.Canceled)
+	}
+	if len(report.Failed()) != 2 {
+		t.Errorf("Failed is %+v, want both changes", report.Failed())
+	}
+	if got := srv.requestsFor(""); len(got) != 0 {
+		t.Errorf("Requests after cancellation are %q, want none", got)
+	}
+}
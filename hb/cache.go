@@ -0,0 +1,337 @@
+package hb
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/internal/atomicfile"
+)
+
+// Cache is the interface of a store for cached API responses. Values are
+// opaque to the store. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key and whether it was found.
+	Get(key string) ([]byte, bool)
+	// Set stores value for key.
+	Set(key string, value []byte)
+	// Delete removes the value stored for key, if any.
+	Delete(key string)
+}
+
+// CachePolicy configures which API responses a Client caches and for how
+// long. Only the responses of GET requests sent by service methods that have
+// a TTL are cached.
+//
+// When a cached response expires and the API had sent an ETag or
+// Last-Modified header with it, the request is sent as a conditional request
+// and the cached response is reused if the API reports it has not been
+// modified.
+//
+// Successful calls to LibraryService methods invalidate the cached libraries
+// (UserService.Library). Since the API does not tell which user an
+// authentication token belongs to, the cached libraries of all users are
+// invalidated.
+type CachePolicy struct {
+	// Cache stores the responses.
+	Cache Cache
+
+	// TTL maps the name of a service method, such as "Anime.Get" or
+	// "User.FavoriteAnime", to the time its responses are considered fresh.
+	TTL map[string]time.Duration
+}
+
+// NewCachePolicy returns a CachePolicy that stores responses in cache and
+// caches the responses of Anime.Get for an hour, Anime.Search for 10 minutes
+// and User.Get and User.FavoriteAnime for 5 minutes. Libraries are not cached
+// unless a TTL is added for "User.Library".
+func NewCachePolicy(cache Cache) *CachePolicy {
+	return &CachePolicy{
+		Cache: cache,
+		TTL: map[string]time.Duration{
+			"Anime.Get":          time.Hour,
+			"Anime.Search":       10 * time.Minute,
+			"User.Get":           5 * time.Minute,
+			"User.FavoriteAnime": 5 * time.Minute,
+		},
+	}
+}
+
+// libraryGenerationKey is the cache key of the counter that is part of the
+// cache key of every library. Incrementing it invalidates all the cached
+// libraries without having to enumerate them.
+const libraryGenerationKey = "hb:library-generation"
+
+// cachedResponse is the representation of a response in a Cache.
+type cachedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	Expires      time.Time   `json:"expires"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+}
+
+func (cr *cachedResponse) response(req *http.Request) *http.Response {
+	header := make(http.Header, len(cr.Header)+1)
+	for k, v := range cr.Header {
+		header[k] = v
+	}
+	header.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        strconv.Itoa(cr.StatusCode) + " " + http.StatusText(cr.StatusCode),
+		StatusCode:    cr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(cr.Body)),
+		ContentLength: int64(len(cr.Body)),
+		Request:       req,
+	}
+}
+
+// key returns the cache key of a request sent by the service method name.
+func (p *CachePolicy) key(name string, req *http.Request) string {
+	key := req.Method + " " + req.URL.String()
+	if name == "User.Library" {
+		key += "#" + strconv.Itoa(p.libraryGeneration())
+	}
+	return key
+}
+
+func (p *CachePolicy) libraryGeneration() int {
+	b, ok := p.Cache.Get(libraryGenerationKey)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.Atoi(string(b))
+	return n
+}
+
+// InvalidateLibraries invalidates all the cached libraries.
+func (p *CachePolicy) InvalidateLibraries() {
+	p.Cache.Set(libraryGenerationKey, []byte(strconv.Itoa(p.libraryGeneration()+1)))
+}
+
+func (p *CachePolicy) get(key string) (*cachedResponse, bool) {
+	b, ok := p.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	cr := new(cachedResponse)
+	if err := json.Unmarshal(b, cr); err != nil {
+		p.Cache.Delete(key)
+		return nil, false
+	}
+	return cr, true
+}
+
+func (p *CachePolicy) set(key string, cr *cachedResponse) {
+	b, err := json.Marshal(cr)
+	if err != nil {
+		return
+	}
+	p.Cache.Set(key, b)
+}
+
+// sendCached sends req like send but serves it from the cache of the Client,
+// if the Client has a CachePolicy that covers the service method that sent
+// it.
+func (c *Client) sendCached(ctx context.Context, req *http.Request) (*http.Response, error) {
+	p := c.Caching
+	if p == nil || p.Cache == nil {
+		return c.send(ctx, req)
+	}
+
+	name := methodName(ctx)
+	if req.Method != "GET" {
+		resp, err := c.send(ctx, req)
+		if err == nil && strings.HasPrefix(name, "Library.") && resp.StatusCode < 300 {
+			p.InvalidateLibraries()
+		}
+		return resp, err
+	}
+
+	ttl, ok := p.TTL[name]
+	if !ok || ttl <= 0 {
+		return c.send(ctx, req)
+	}
+
+	key := p.key(name, req)
+	cached, ok := p.get(key)
+	if ok && time.Now().Before(cached.Expires) {
+		return cached.response(req), nil
+	}
+
+	r := req
+	if ok && (cached.ETag != "" || cached.LastModified != "") {
+		r = req.Clone(ctx)
+		if cached.ETag != "" {
+			r.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			r.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		cached.Expires = time.Now().Add(ttl)
+		p.set(key, cached)
+		return cached.response(req), nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	p.set(key, &cachedResponse{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header,
+		Body:         body,
+		Expires:      time.Now().Add(ttl),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	return resp, nil
+}
+
+// MemoryCache is an in-memory Cache that holds up to a maximum number of
+// entries and evicts the least recently used one when it is full.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key   string
+	value []byte
+}
+
+// NewMemoryCache returns a MemoryCache that holds up to maxEntries entries. If
+// maxEntries is zero or less, the cache is not limited.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored for key and marks it as recently used.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*memoryCacheEntry).value, true
+}
+
+// Set stores value for key, evicting the least recently used entry if the
+// cache is full.
+func (c *MemoryCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*memoryCacheEntry).value = value
+		return
+	}
+	c.items[key] = c.ll.PushFront(&memoryCacheEntry{key: key, value: value})
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete removes the value stored for key.
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// DiskCache is a Cache that stores each entry in a file of a directory so
+// that it can be shared by many processes and survive restarts.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache that stores its entries in dir. The
+// directory is created if it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the value stored for key.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	b, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Set stores value for key. The value is first written to a temporary file
+// and then renamed so that readers never see a partially written entry.
+func (c *DiskCache) Set(key string, value []byte) {
+	atomicfile.WriteFile(c.path(key), value)
+}
+
+// Delete removes the value stored for key.
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
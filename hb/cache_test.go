@@ -0,0 +1,177 @@
+package hb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClient_Caching(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Caching = NewCachePolicy(NewMemoryCache(10))
+
+	calls := 0
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"title":"Log Horizon"}`)
+	})
+
+	for i := 0; i < 3; i++ {
+		anime, resp, err := client.Anime.Get("log-horizon", "")
+		if err != nil {
+			t.Fatalf("Anime.Get returned error %v", err)
+		}
+		if got, want := anime.Title, "Log Horizon"; got != want {
+			t.Errorf("Anime.Get title is %v, want %v", got, want)
+		}
+		if got, want := resp.Header.Get("X-From-Cache") == "1", i > 0; got != want {
+			t.Errorf("Anime.Get #%d served from cache is %v, want %v", i+1, got, want)
+		}
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("Anime.Get sent %v requests, want %v", got, want)
+	}
+
+	// A different title language preference is a different response.
+	client.Anime.Get("log-horizon", "english")
+	if got, want := calls, 2; got != want {
+		t.Errorf("Anime.Get sent %v requests, want %v", got, want)
+	}
+}
+
+func TestClient_Caching_errorsNotCached(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Caching = NewCachePolicy(NewMemoryCache(10))
+
+	calls := 0
+	mux.HandleFunc("/api/v1/users/TestUser", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.User.Get("TestUser"); err == nil {
+			t.Error("Expected HTTP 404 error.")
+		}
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("User.Get sent %v requests, want %v", got, want)
+	}
+}
+
+func TestClient_Caching_revalidate(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Caching = NewCachePolicy(NewMemoryCache(10))
+	client.Caching.TTL["User.FavoriteAnime"] = time.Nanosecond
+
+	calls := 0
+	mux.HandleFunc("/api/v1/users/TestUser/favorite_anime", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprintf(w, `[{"title":"Nichijou"}]`)
+	})
+
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond)
+		anime, _, err := client.User.FavoriteAnime("TestUser")
+		if err != nil {
+			t.Fatalf("User.FavoriteAnime returned error %v", err)
+		}
+		if len(anime) != 1 || anime[0].Title != "Nichijou" {
+			t.Errorf("User.FavoriteAnime #%d returned %v", i+1, anime)
+		}
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("User.FavoriteAnime sent %v requests, want %v", got, want)
+	}
+}
+
+func TestClient_Caching_libraryInvalidation(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Caching = NewCachePolicy(NewMemoryCache(10))
+	client.Caching.TTL["User.Library"] = time.Hour
+
+	calls := 0
+	mux.HandleFunc("/api/v1/users/TestUser/library", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `[]`)
+	})
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":7622}`)
+	})
+
+	client.User.Library("TestUser", "")
+	client.User.Library("TestUser", "")
+	if got, want := calls, 1; got != want {
+		t.Errorf("User.Library sent %v requests, want %v", got, want)
+	}
+
+	if _, _, err := client.Library.Update("log-horizon", "valid_user_token", nil); err != nil {
+		t.Fatalf("Library.Update returned error %v", err)
+	}
+
+	client.User.Library("TestUser", "")
+	if got, want := calls, 2; got != want {
+		t.Errorf("User.Library after Library.Update sent %v requests, want %v", got, want)
+	}
+}
+
+func TestMemoryCache_evict(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	c.Get("a")
+	c.Set("c", []byte("3"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted.")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Expected entry %q to be in the cache.", key)
+		}
+	}
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("Len is %v, want %v", got, want)
+	}
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected deleted entry to be removed.")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hb-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error %v", err)
+	}
+
+	c.Set("GET http://example.com/a", []byte("value"))
+	got, ok := c.Get("GET http://example.com/a")
+	if !ok || string(got) != "value" {
+		t.Errorf("Get returned %q, %v, want %q, true", got, ok, "value")
+	}
+
+	c.Delete("GET http://example.com/a")
+	if _, ok := c.Get("GET http://example.com/a"); ok {
+		t.Error("Expected deleted entry to be removed.")
+	}
+}
@@ -0,0 +1,129 @@
+package hb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the layout of the dates that the API returns without a time,
+// such as the airing dates of an anime.
+const dateLayout = "2006-01-02"
+
+// dateLayouts are the layouts that Date accepts, tried in order.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	dateLayout,
+}
+
+// Date is a date or a point in time returned by the API. The API uses
+// different forms for them: a date such as "2013-10-05", an RFC 3339 time
+// such as "2016-05-01T12:00:00.000Z", null or the empty string when there is
+// no date. Date accepts all of them. Fields of type *Date are nil when the
+// API returns null and the zero Date when it returns the empty string.
+//
+// Dates without a time are in UTC.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a date in any of the forms of the API. The empty string
+// results in the zero Date.
+func ParseDate(s string) (Date, error) {
+	if s == "" {
+		return Date{}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Date{t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("hb: invalid date %q", s)
+}
+
+// time returns the time of d or the zero time if d is nil.
+func (d *Date) time() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	return d.Time
+}
+
+// hasClock reports whether d has a time of day, as opposed to a date that
+// the API returned without a time.
+func (d Date) hasClock() bool {
+	h, m, s := d.Clock()
+	return h != 0 || m != 0 || s != 0 || d.Nanosecond() != 0 || d.Location() != time.UTC
+}
+
+// String returns the date as "2006-01-02" if it has no time and in RFC 3339
+// otherwise. The zero Date is the empty string.
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case !d.hasClock():
+		return d.Format(dateLayout)
+	}
+	return d.Format(time.RFC3339Nano)
+}
+
+// MarshalJSON implements json.Marshaler. The zero Date is encoded as null,
+// like the API does.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts the forms accepted
+// by ParseDate as well as null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("hb: invalid date %s", data)
+	}
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// Season is an anime season. Seasons follow the quarters of the year,
+// starting with winter from January to March.
+type Season string
+
+// Anime seasons.
+const (
+	Winter Season = "winter"
+	Spring Season = "spring"
+	Summer Season = "summer"
+	Fall   Season = "fall"
+)
+
+// seasons are the seasons in the order of the quarters of the year.
+var seasons = [...]Season{Winter, Spring, Summer, Fall}
+
+// SeasonOf returns the season and the year of t.
+func SeasonOf(t time.Time) (Season, int) {
+	return seasons[(t.Month()-1)/3], t.Year()
+}
+
+// Start returns the first day of the season s of year, or the zero time if s
+// is not a season.
+func (s Season) Start(year int) time.Time {
+	for i, season := range seasons {
+		if season == s {
+			return time.Date(year, time.Month(i*3+1), 1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return time.Time{}
+}
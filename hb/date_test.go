@@ -0,0 +1,106 @@
+package hb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"2013-10-05", time.Date(2013, 10, 5, 0, 0, 0, 0, time.UTC)},
+		{"2016-05-01T12:00:00Z", time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"2016-05-01T12:00:00.250Z", time.Date(2016, 5, 1, 12, 0, 0, 250e6, time.UTC)},
+		{"2016-05-01T21:00:00+09:00", time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"2016-05-01T12:00:00", time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if err != nil {
+			t.Errorf("ParseDate(%q) returned error %v", tt.in, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) is %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseDate("05/10/2013"); err == nil {
+		t.Error("Expected invalid date error.")
+	}
+}
+
+func TestDate_JSON(t *testing.T) {
+	var a Anime
+	in := `{"started_airing":"2013-10-05","finished_airing":null}`
+	if err := json.Unmarshal([]byte(in), &a); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	if want := time.Date(2013, 10, 5, 0, 0, 0, 0, time.UTC); a.StartedAiring == nil || !a.StartedAiring.Equal(want) {
+		t.Errorf("Unmarshal started_airing is %v, want %v", a.StartedAiring, want)
+	}
+	if a.FinishedAiring != nil {
+		t.Errorf("Unmarshal null finished_airing is %v, want nil", a.FinishedAiring)
+	}
+
+	var e LibraryEntry
+	if err := json.Unmarshal([]byte(`{"last_watched":""}`), &e); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	if e.LastWatched == nil || !e.LastWatched.IsZero() {
+		t.Errorf("Unmarshal empty last_watched is %v, want zero", e.LastWatched)
+	}
+	if err := json.Unmarshal([]byte(`{"last_watched":"2016-05-01T12:00:00.000Z"}`), &e); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	if want := time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC); !e.LastWatched.Equal(want) {
+		t.Errorf("Unmarshal last_watched is %v, want %v", e.LastWatched, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"last_watched":20160501}`), &e); err == nil {
+		t.Error("Expected invalid date error.")
+	}
+
+	b, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatalf("Marshal returned error %v", err)
+	}
+	if got, want := string(b), `{"started_airing":"2013-10-05"}`; got != want {
+		t.Errorf("Marshal is %s, want %s", got, want)
+	}
+	b, err = json.Marshal(e.LastWatched)
+	if err != nil {
+		t.Fatalf("Marshal returned error %v", err)
+	}
+	if got, want := string(b), `"2016-05-01T12:00:00Z"`; got != want {
+		t.Errorf("Marshal is %s, want %s", got, want)
+	}
+}
+
+func TestSeasonOf(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want Season
+	}{
+		{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), Winter},
+		{time.Date(2016, 4, 10, 0, 0, 0, 0, time.UTC), Spring},
+		{time.Date(2016, 9, 30, 0, 0, 0, 0, time.UTC), Summer},
+		{time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC), Fall},
+	}
+	for _, tt := range tests {
+		season, year := SeasonOf(tt.t)
+		if season != tt.want || year != 2016 {
+			t.Errorf("SeasonOf(%v) is %v %v, want %v 2016", tt.t, season, year, tt.want)
+		}
+		if start := season.Start(year); start.After(tt.t) || !tt.t.Before(start.AddDate(0, 3, 0)) {
+			t.Errorf("%v %v starts at %v, which doesn't include %v", season, year, start, tt.t)
+		}
+	}
+
+	if start := Season("monsoon").Start(2016); !start.IsZero() {
+		t.Errorf("Start of an invalid season is %v, want zero", start)
+	}
+}
@@ -11,5 +11,14 @@ anime entries that are contained in the library of the user "cybrox":
 	// handle err
 
 	// do something with entries
+
+Every service method has a variant that accepts a context.Context, such as
+LibraryContext for Library, which can be used to cancel a request or set a
+deadline on it:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entries, _, err := c.User.LibraryContext(ctx, "cybrox", hb.StatusCurrentlyWatching)
 */
 package hb
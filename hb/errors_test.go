@@ -0,0 +1,88 @@
+package hb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorResponse_Is(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusServiceUnavailable, ErrServer},
+	}
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrServer}
+
+	for _, tt := range tests {
+		err := error(&ErrorResponse{Response: &http.Response{StatusCode: tt.status}})
+		for _, target := range sentinels {
+			if got, want := errors.Is(err, target), target == tt.want; got != want {
+				t.Errorf("errors.Is(%d response, %v) is %v, want %v", tt.status, target, got, want)
+			}
+		}
+	}
+
+	err := &ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadRequest}}
+	for _, target := range sentinels {
+		if errors.Is(err, target) {
+			t.Errorf("errors.Is(400 response, %v) is true, want false", target)
+		}
+	}
+}
+
+func TestAnimeService_Get_errNotFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	_, _, err := client.Anime.Get("invalid-anime", "")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Anime.Get returned error %v, want it to match %v", err, ErrNotFound)
+	}
+
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("Anime.Get returned error %T, want *ErrorResponse", err)
+	}
+	if got, want := errResp.Method, "GET"; got != want {
+		t.Errorf("ErrorResponse.Method is %v, want %v", got, want)
+	}
+	if got, want := errResp.URL.String(), server.URL+"/api/v1/anime/invalid-anime"; got != want {
+		t.Errorf("ErrorResponse.URL is %v, want %v", got, want)
+	}
+	// Non-JSON bodies are kept as the message.
+	if got, want := errResp.Message, "not found"; got != want {
+		t.Errorf("ErrorResponse.Message is %q, want %q", got, want)
+	}
+	if got, want := string(errResp.Body), "not found\n"; got != want {
+		t.Errorf("ErrorResponse.Body is %q, want %q", got, want)
+	}
+}
+
+func TestLibraryService_Update_errUnauthorized(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "Invalid authentication token"}`, http.StatusUnauthorized)
+	})
+
+	_, _, err := client.Library.Update("log-horizon", "invalid_user_token", nil)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Library.Update returned error %v, want it to match %v", err, ErrUnauthorized)
+	}
+	want := fmt.Sprintf("POST %v/api/v1/libraries/log-horizon: 401 Invalid authentication token", server.URL)
+	if got := err.Error(); got != want {
+		t.Errorf("ErrorResponse is %v, want %v", got, want)
+	}
+}
@@ -103,11 +103,11 @@ func ExampleUserService_Feed() {
 		log.Fatal(err)
 	}
 	for _, s := range stories {
-		if s.StoryType == "comment" {
+		if s.StoryType == hb.StoryComment {
 			for _, ss := range s.Substories {
-				if ss.SubstoryType == "comment" {
+				if comment, ok := ss.AsComment(); ok {
 					fmt.Printf("%v said to %v:\n", s.Poster.Name, s.User.Name)
-					fmt.Printf("%v\n\n", ss.Comment)
+					fmt.Printf("%v\n\n", comment)
 				}
 			}
 		}
@@ -148,14 +148,14 @@ func ExampleLibraryService_Update() {
 	// episodes watched as 5.
 	_, _, err = c.Library.Update("nichijou", token, &hb.Entry{
 		Status:          hb.StatusCurrentlyWatching,
-		EpisodesWatched: 5,
+		EpisodesWatched: hb.Int(5),
 	})
 	checkErr(err)
 
 	// Update Nichijou, setting status as completed and setting a note.
 	_, _, err = c.Library.Update("nichijou", token, &hb.Entry{
 		Status: hb.StatusCompleted,
-		Notes:  "crazy",
+		Notes:  hb.String("crazy"),
 	})
 	checkErr(err)
 
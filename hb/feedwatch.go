@@ -0,0 +1,394 @@
+package hb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/internal/atomicfile"
+)
+
+// FeedEventType is the type of a FeedEvent.
+type FeedEventType string
+
+// Feed event types.
+const (
+	// EventEpisodeWatched is sent when a user watches an episode. Episode
+	// holds the episode number.
+	EventEpisodeWatched FeedEventType = "episode_watched"
+
+	// EventStatusChanged is sent when a user changes the status of a
+	// library entry. Status holds the new status.
+	EventStatusChanged FeedEventType = "status_changed"
+
+	// EventFollowed is sent when a user follows another user. FollowedUser
+	// holds the followed user.
+	EventFollowed FeedEventType = "followed"
+
+	// EventComment is sent when a user posts a comment. Comment holds the
+	// comment.
+	EventComment FeedEventType = "comment"
+
+	// EventOther is sent for any other activity.
+	EventOther FeedEventType = "other"
+)
+
+// FeedEvent is a new activity in the feed of a user, which is a substory of
+// a story or a story without substories.
+type FeedEvent struct {
+	Type FeedEventType `json:"type"`
+
+	// Username is the user whose feed the event comes from.
+	Username string `json:"username"`
+
+	// Story is the story of the event and Substory its substory, which is
+	// the zero Substory for stories without substories.
+	Story    Story    `json:"story"`
+	Substory Substory `json:"substory"`
+
+	// Anime or Manga is the media of the story, if any.
+	Anime *Anime `json:"anime,omitempty"`
+	Manga *Manga `json:"manga,omitempty"`
+
+	Episode      int           `json:"episode,omitempty"`
+	Status       LibraryStatus `json:"status,omitempty"`
+	FollowedUser *UserMini     `json:"followed_user,omitempty"`
+	Comment      string        `json:"comment,omitempty"`
+}
+
+// newFeedEvent returns the event of the substory sub of story, where sub is
+// nil for stories without substories.
+func newFeedEvent(username string, story Story, sub *Substory) FeedEvent {
+	e := FeedEvent{Type: EventOther, Username: username, Story: story, Anime: story.Media, Manga: story.Manga}
+	e.Story.Substories = nil
+	if sub == nil {
+		if story.StoryType == StoryComment {
+			e.Type = EventComment
+		}
+		return e
+	}
+	e.Substory = *sub
+	if n, ok := sub.AsWatchedEpisode(); ok {
+		e.Type, e.Episode = EventEpisodeWatched, n
+	} else if status, ok := sub.AsStatusUpdate(); ok {
+		e.Type, e.Status = EventStatusChanged, status
+	} else if user, ok := sub.AsFollowed(); ok {
+		e.Type, e.FollowedUser = EventFollowed, user
+	} else if comment, ok := sub.AsComment(); ok {
+		e.Type, e.Comment = EventComment, comment
+	}
+	return e
+}
+
+// FeedCursor is the position of a FeedWatcher in the feed of a user: the
+// highest story and substory IDs it has seen. Activities with higher IDs are
+// new. It relies on the API assigning increasing IDs.
+type FeedCursor struct {
+	StoryID    int `json:"story_id"`
+	SubstoryID int `json:"substory_id"`
+}
+
+// CursorStore stores the cursors of a FeedWatcher so that a watcher that is
+// restarted doesn't send the events it has already sent.
+type CursorStore interface {
+	// LoadCursor returns the cursor of the feed of a user and whether
+	// there is one.
+	LoadCursor(username string) (FeedCursor, bool, error)
+
+	// SaveCursor stores the cursor of the feed of a user.
+	SaveCursor(username string, c FeedCursor) error
+}
+
+// memoryCursorStore is the CursorStore of watchers without a Store.
+type memoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]FeedCursor
+}
+
+func (s *memoryCursorStore) LoadCursor(username string) (FeedCursor, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cursors[username]
+	return c, ok, nil
+}
+
+func (s *memoryCursorStore) SaveCursor(username string, c FeedCursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cursors == nil {
+		s.cursors = make(map[string]FeedCursor)
+	}
+	s.cursors[username] = c
+	return nil
+}
+
+// FileCursorStore is a CursorStore that keeps the cursors of all users in a
+// JSON file.
+type FileCursorStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCursorStore returns a FileCursorStore that keeps the cursors in the
+// file at path. The file is created when the first cursor is saved.
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+func (s *FileCursorStore) load() (map[string]FeedCursor, error) {
+	cursors := make(map[string]FeedCursor)
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &cursors); err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
+
+// LoadCursor returns the cursor of the feed of a user and whether there is
+// one.
+func (s *FileCursorStore) LoadCursor(username string) (FeedCursor, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors, err := s.load()
+	if err != nil {
+		return FeedCursor{}, false, err
+	}
+	c, ok := cursors[username]
+	return c, ok, nil
+}
+
+// SaveCursor stores the cursor of the feed of a user. The file is never left
+// partially written.
+func (s *FileCursorStore) SaveCursor(username string, c FeedCursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors, err := s.load()
+	if err != nil {
+		return err
+	}
+	cursors[username] = c
+	b, err := json.Marshal(cursors)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, b)
+}
+
+// FeedWatcher polls the activity feeds of users and sends the new
+// activities as events. Activities are deduplicated by their story and
+// substory IDs, see FeedCursor.
+//
+// A FeedWatcher must not be run more than once at the same time.
+type FeedWatcher struct {
+	// Interval is the time between the polls of the feed of each user.
+	// Defaults to a minute.
+	Interval time.Duration
+
+	// MaxBackoff is the maximum time between the polls of the feed of a
+	// user when polling it fails. The time doubles from Interval on each
+	// consecutive failure. Defaults to 30 minutes.
+	MaxBackoff time.Duration
+
+	// Backfill makes the first poll of a feed without a cursor send the
+	// activities already in the feed. By default they are skipped and only
+	// activities that happen after the first poll are sent.
+	Backfill bool
+
+	// Store stores the cursors of the feeds. If nil, the cursors are kept in
+	// memory and are lost when the program exits.
+	Store CursorStore
+
+	// OnError, if set, is called with the errors of polling the feed of a
+	// user. Polling continues after errors.
+	OnError func(username string, err error)
+
+	client    *Client
+	usernames []string
+	memory    memoryCursorStore
+}
+
+// NewFeedWatcher returns a FeedWatcher that watches the feeds of the users
+// with the given names.
+func NewFeedWatcher(client *Client, usernames ...string) *FeedWatcher {
+	return &FeedWatcher{client: client, usernames: usernames}
+}
+
+func (w *FeedWatcher) store() CursorStore {
+	if w.Store == nil {
+		return &w.memory
+	}
+	return w.Store
+}
+
+func (w *FeedWatcher) interval() time.Duration {
+	if w.Interval <= 0 {
+		return time.Minute
+	}
+	return w.Interval
+}
+
+func (w *FeedWatcher) maxBackoff() time.Duration {
+	if w.MaxBackoff <= 0 {
+		return 30 * time.Minute
+	}
+	return w.MaxBackoff
+}
+
+// PollUser polls the feed of a user once and returns the new events, oldest
+// first. The cursor is saved before the events are returned.
+func (w *FeedWatcher) PollUser(ctx context.Context, username string) ([]FeedEvent, error) {
+	events, cursor, err := w.poll(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.store().SaveCursor(username, cursor); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// poll returns the new events of the feed of a user and the cursor after
+// them.
+func (w *FeedWatcher) poll(ctx context.Context, username string) ([]FeedEvent, FeedCursor, error) {
+	cursor, ok, err := w.store().LoadCursor(username)
+	if err != nil {
+		return nil, cursor, err
+	}
+	stories, _, err := w.client.User.FeedContext(ctx, username)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	skip := !ok && !w.Backfill
+	next := cursor
+	var events []FeedEvent
+	// The feed is newest first.
+	for i := len(stories) - 1; i >= 0; i-- {
+		story := stories[i]
+		if story.ID > next.StoryID {
+			next.StoryID = story.ID
+		}
+		if len(story.Substories) == 0 {
+			if story.ID > cursor.StoryID && !skip {
+				events = append(events, newFeedEvent(username, story, nil))
+			}
+			continue
+		}
+		subs := make([]Substory, len(story.Substories))
+		copy(subs, story.Substories)
+		sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+		for j := range subs {
+			if subs[j].ID > next.SubstoryID {
+				next.SubstoryID = subs[j].ID
+			}
+			if subs[j].ID > cursor.SubstoryID && !skip {
+				events = append(events, newFeedEvent(username, story, &subs[j]))
+			}
+		}
+	}
+	return events, next, nil
+}
+
+// Run polls the feeds until ctx is done, calling fn with each new event,
+// oldest first. The cursor of a feed is saved after fn has returned for all
+// its new events, so events may be sent again if the program exits in
+// between. It is not saved if ctx is done by then, since fn may not have
+// handled the events. Run returns the error of ctx.
+func (w *FeedWatcher) Run(ctx context.Context, fn func(FeedEvent)) error {
+	next := make(map[string]time.Time, len(w.usernames))
+	failures := make(map[string]int, len(w.usernames))
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		wake := now.Add(w.interval())
+		for _, username := range w.usernames {
+			if t, ok := next[username]; ok && t.After(now) {
+				if t.Before(wake) {
+					wake = t
+				}
+				continue
+			}
+			err := w.runOnce(ctx, username, fn)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			delay := w.interval()
+			if err != nil {
+				failures[username]++
+				delay = w.backoff(failures[username])
+				if w.OnError != nil {
+					w.OnError(username, err)
+				}
+			} else {
+				failures[username] = 0
+			}
+			next[username] = time.Now().Add(delay)
+			if next[username].Before(wake) {
+				wake = next[username]
+			}
+		}
+		timer.Reset(time.Until(wake))
+	}
+}
+
+func (w *FeedWatcher) runOnce(ctx context.Context, username string, fn func(FeedEvent)) error {
+	events, cursor, err := w.poll(ctx, username)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		fn(e)
+	}
+	// Once ctx is done fn may not have delivered the events, such as in
+	// Events, so the cursor is not saved and they are sent again.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.store().SaveCursor(username, cursor)
+}
+
+// backoff returns the time until the next poll of a feed after the given
+// number of consecutive failures.
+func (w *FeedWatcher) backoff(failures int) time.Duration {
+	d := w.interval()
+	for i := 0; i < failures && d < w.maxBackoff(); i++ {
+		d *= 2
+	}
+	if d > w.maxBackoff() {
+		d = w.maxBackoff()
+	}
+	return d
+}
+
+// Events runs the watcher in a new goroutine and returns a channel that
+// receives the new events. The channel is closed when ctx is done.
+func (w *FeedWatcher) Events(ctx context.Context) <-chan FeedEvent {
+	ch := make(chan FeedEvent)
+	go func() {
+		defer close(ch)
+		w.Run(ctx, func(e FeedEvent) {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return ch
+}
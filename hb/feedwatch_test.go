@@ -0,0 +1,237 @@
+package hb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testFeed serves the feed of "TestUser" from stories, newest first, which
+// tests can change while a watcher polls it.
+type testFeed struct {
+	mu      sync.Mutex
+	stories []Story
+	fail    int
+}
+
+func (f *testFeed) add(s Story) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stories = append([]Story{s}, f.stories...)
+}
+
+func (f *testFeed) serve(t *testing.T) {
+	mux.HandleFunc("/api/v1/users/TestUser/feed", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.fail > 0 {
+			f.fail--
+			http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(f.stories)
+	})
+}
+
+func TestFeedWatcher_PollUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	feed := &testFeed{stories: []Story{{ID: 1, StoryType: "comment", Substories: []Substory{{ID: 2, SubstoryType: "comment", Comment: "old"}}}}}
+	feed.serve(t)
+
+	w := NewFeedWatcher(client, "TestUser")
+	events, err := w.PollUser(context.Background(), "TestUser")
+	if err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("First poll returned %+v, want no events without Backfill", events)
+	}
+
+	anime := &Anime{ID: 7622, Title: "Log Horizon"}
+	feed.add(Story{ID: 3, StoryType: "media_story", Media: anime, Substories: []Substory{
+		{ID: 5, SubstoryType: "watched_episode", EpisodeNumber: "4"},
+		{ID: 4, SubstoryType: "watchlist_status_update", NewStatus: "currently_watching"},
+	}})
+	feed.add(Story{ID: 6, StoryType: "followed", Substories: []Substory{
+		{ID: 7, SubstoryType: "followed", FollowedUser: &UserMini{Name: "cybrox"}},
+		{ID: 8, SubstoryType: "unknown_type"},
+	}})
+
+	events, err = w.PollUser(context.Background(), "TestUser")
+	if err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+	var types []FeedEventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	wantTypes := []FeedEventType{EventStatusChanged, EventEpisodeWatched, EventFollowed, EventOther}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("PollUser returned events %v, want %v", types, wantTypes)
+	}
+	if e := events[0]; e.Status != StatusCurrentlyWatching || e.Anime == nil || e.Anime.ID != 7622 || e.Username != "TestUser" {
+		t.Errorf("Status event is %+v", e)
+	}
+	if e := events[1]; e.Episode != 4 || e.Substory.ID != 5 {
+		t.Errorf("Episode event is %+v", e)
+	}
+	if e := events[2]; e.FollowedUser == nil || e.FollowedUser.Name != "cybrox" {
+		t.Errorf("Followed event is %+v", e)
+	}
+
+	events, err = w.PollUser(context.Background(), "TestUser")
+	if err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Poll without new activity returned %+v", events)
+	}
+}
+
+func TestFeedWatcher_Backfill(t *testing.T) {
+	setup()
+	defer teardown()
+
+	feed := &testFeed{stories: []Story{
+		{ID: 3, StoryType: "comment"},
+		{ID: 1, StoryType: "media_story", Substories: []Substory{{ID: 2, SubstoryType: "comment", Comment: "hi"}}},
+	}}
+	feed.serve(t)
+
+	w := NewFeedWatcher(client, "TestUser")
+	w.Backfill = true
+	events, err := w.PollUser(context.Background(), "TestUser")
+	if err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+	if len(events) != 2 || events[0].Comment != "hi" || events[1].Type != EventComment || events[1].Story.ID != 3 {
+		t.Errorf("PollUser returned %+v, want the comments of the feed", events)
+	}
+}
+
+func TestFeedWatcher_FileCursorStore(t *testing.T) {
+	setup()
+	defer teardown()
+
+	feed := &testFeed{}
+	feed.serve(t)
+
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursors.json"))
+	if _, ok, err := store.LoadCursor("TestUser"); ok || err != nil {
+		t.Fatalf("LoadCursor of a missing file returned %v, %v", ok, err)
+	}
+
+	w := NewFeedWatcher(client, "TestUser")
+	w.Store = store
+	if _, err := w.PollUser(context.Background(), "TestUser"); err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+	feed.add(Story{ID: 10, Substories: []Substory{{ID: 11, SubstoryType: "comment"}}})
+	if _, err := w.PollUser(context.Background(), "TestUser"); err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+
+	// A restarted watcher continues from the stored cursor.
+	feed.add(Story{ID: 12, Substories: []Substory{{ID: 13, SubstoryType: "comment"}}})
+	w = NewFeedWatcher(client, "TestUser")
+	w.Store = NewFileCursorStore(store.path)
+	events, err := w.PollUser(context.Background(), "TestUser")
+	if err != nil {
+		t.Fatalf("PollUser returned error %v", err)
+	}
+	if len(events) != 1 || events[0].Substory.ID != 13 {
+		t.Errorf("PollUser after restart returned %+v, want only substory 13", events)
+	}
+	c, ok, err := store.LoadCursor("TestUser")
+	if want := (FeedCursor{StoryID: 12, SubstoryID: 13}); !ok || err != nil || c != want {
+		t.Errorf("LoadCursor returned %+v, %v, %v, want %+v", c, ok, err, want)
+	}
+}
+
+func TestFeedWatcher_Events(t *testing.T) {
+	setup()
+	defer teardown()
+
+	feed := &testFeed{fail: 1}
+	feed.serve(t)
+
+	var mu sync.Mutex
+	var errs int
+	w := NewFeedWatcher(client, "TestUser")
+	w.Interval = 5 * time.Millisecond
+	w.OnError = func(username string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs++
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := w.Events(ctx)
+
+	// Wait for the first successful poll before adding activity.
+	for {
+		if _, ok, _ := w.store().LoadCursor("TestUser"); ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	feed.add(Story{ID: 1, Substories: []Substory{{ID: 2, SubstoryType: "watched_episode", EpisodeNumber: "1"}}})
+
+	select {
+	case e := <-events:
+		if e.Type != EventEpisodeWatched || e.Episode != 1 {
+			t.Errorf("Events sent %+v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("Events sent nothing")
+	}
+	cancel()
+	for range events {
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if errs != 1 {
+		t.Errorf("OnError was called %v times, want 1", errs)
+	}
+}
+
+func TestFeedWatcher_Run_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	feed := new(testFeed)
+	feed.serve(t)
+	feed.add(Story{ID: 1, Substories: []Substory{{ID: 2, SubstoryType: "watched_episode", EpisodeNumber: "1"}}})
+
+	w := NewFeedWatcher(client, "TestUser")
+	w.Backfill = true
+	ctx, cancel := context.WithCancel(context.Background())
+	// The event is not delivered, as in Events when ctx is done.
+	err := w.Run(ctx, func(FeedEvent) { cancel() })
+	if err != context.Canceled {
+		t.Errorf("Run returned error %v, want %v", err, context.Canceled)
+	}
+
+	if c, ok, _ := w.store().LoadCursor("TestUser"); ok {
+		t.Errorf("Cursor %+v was saved for events that were not delivered", c)
+	}
+}
+
+func TestFeedWatcher_backoff(t *testing.T) {
+	w := &FeedWatcher{Interval: time.Second, MaxBackoff: 5 * time.Second}
+	for failures, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := w.backoff(failures); got != want {
+			t.Errorf("backoff(%v) is %v, want %v", failures, got, want)
+		}
+	}
+}
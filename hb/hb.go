@@ -2,12 +2,17 @@ package hb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
 const (
@@ -21,6 +26,39 @@ type Client struct {
 
 	BaseURL *url.URL
 
+	// Retry is the policy used to retry requests that failed because of a
+	// transient error. If nil, requests are never retried.
+	Retry *RetryPolicy
+
+	// RateLimiter, if set, limits the rate of the requests sent by all the
+	// services of the Client. Every attempt of a request waits for it.
+	RateLimiter *RateLimiter
+
+	// Caching, if set, makes the Client cache the responses of read-only
+	// service methods.
+	Caching *CachePolicy
+
+	// Middleware intercepts every API call made by the Client. The first
+	// Middleware is the outermost one.
+	Middleware []Middleware
+
+	// Logger, if set, is used to log the API calls made by the Client. The
+	// values of authentication tokens and passwords are never logged.
+	Logger *slog.Logger
+
+	// Debug makes the Client log the full request and response bodies at
+	// debug level. It has no effect if Logger is nil.
+	Debug bool
+
+	// Session, if set, holds the authentication token used by the methods
+	// that don't take one, such as LibraryService.UpdateWithSession. It is
+	// set by UserService.Login.
+	Session *Session
+
+	// Concurrency is the maximum number of requests that batch methods, such
+	// as AnimeService.GetMany, send at the same time. Defaults to 4.
+	Concurrency int
+
 	User    *UserService
 	Anime   *AnimeService
 	Library *LibraryService
@@ -99,13 +137,46 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 // occurred both the response and the error will be returned in case the caller
 // wishes to further inspect the response. If v is passed as an argument, then
 // the API response is JSON decoded and stored to v.
+//
+// The request is sent using the context of req. Use DoContext to send it with
+// a different context.
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := c.client.Do(req)
+	return c.DoContext(req.Context(), req, v)
+}
+
+// DoContext is like Do but sends the request bound to ctx. If ctx is canceled
+// or its deadline is exceeded before the response is received, the error
+// returned is the context's error.
+//
+// If the Client has a CachePolicy, responses of the service methods it covers
+// are served from its cache while they are fresh. If the Client has a
+// RateLimiter, the request waits for it before it is sent. If the Client has
+// a Retry policy, requests that fail because of a transient error are sent
+// again according to it. The call passes through the Middleware of the Client
+// before any of these.
+func (c *Client) DoContext(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	call := &Call{Method: methodName(ctx), Request: req, Result: v}
+	return c.handler()(ctx, call)
+}
+
+// do is the Handler that sends call.Request and decodes the API response into
+// call.Result. It is wrapped by the Middleware of the Client.
+func (c *Client) do(ctx context.Context, call *Call) (resp *http.Response, err error) {
+	if c.Logger != nil {
+		defer func(start time.Time) {
+			c.logCall(ctx, call, resp, err, time.Since(start))
+		}(time.Now())
+	}
+
+	req, v := call.Request, call.Result
+	c.dumpRequest(ctx, req)
+	resp, err = c.sendCached(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
+	c.dumpResponse(ctx, resp)
 
 	err = checkResponse(resp)
 	if err != nil {
@@ -114,33 +185,163 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 
 	if v != nil {
 		err = json.NewDecoder(resp.Body).Decode(v)
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			err = ctxErr
+		}
 	}
 	return resp, err
 }
 
+type methodNameKey struct{}
+
+// withMethodName returns a copy of ctx that carries the name of the service
+// method, such as "Anime.Get", that sends a request.
+func withMethodName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, methodNameKey{}, name)
+}
+
+// methodName returns the name of the service method carried by ctx or the
+// empty string if the request was not sent by a service method.
+func methodName(ctx context.Context) string {
+	name, _ := ctx.Value(methodNameKey{}).(string)
+	return name
+}
+
+// send sends req bound to ctx and retries it according to the Retry policy of
+// the Client. The response of the last attempt is returned.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		r := req.WithContext(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		if c.RateLimiter != nil {
+			if _, err := c.RateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.client.Do(r)
+		if err != nil {
+			// If the context has been canceled, its error is more useful
+			// than the one returned by the HTTP client.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+		}
+
+		wait, ok := c.Retry.retry(attempt, r, resp, err)
+		if !ok {
+			return resp, err
+		}
+		c.logRetry(ctx, r, attempt, resp, err, wait)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
 // checkResponse checks the API response for errors. A response is considered an
 // error if it has status code outside the 200 range. API error responses are
-// expected to have a JSON response body that maps to ErrorResponse. Other
-// response bodies are ignored.
+// expected to have a JSON response body that maps to ErrorResponse. If the
+// body is not JSON, it is used as the error message as is.
 func checkResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
 	}
 	errorResponse := &ErrorResponse{Response: r}
+	if r.Request != nil {
+		errorResponse.Method = r.Request.Method
+		errorResponse.URL = r.Request.URL
+	}
 	body, err := ioutil.ReadAll(r.Body)
 	if err == nil && body != nil {
-		json.Unmarshal(body, errorResponse)
+		errorResponse.Body = body
+		if err := json.Unmarshal(body, errorResponse); err != nil {
+			errorResponse.Message = strings.TrimSpace(string(body))
+		}
 	}
 	return errorResponse
 }
 
-// ErrorResponse represents a Hummingbird API error response.
+// Errors that an ErrorResponse matches, using errors.Is, depending on the
+// status code of the API response.
+var (
+	// ErrNotFound is matched by 404 responses.
+	ErrNotFound = errors.New("hb: not found")
+	// ErrUnauthorized is matched by 401 responses, for example when an
+	// authentication token is invalid.
+	ErrUnauthorized = errors.New("hb: unauthorized")
+	// ErrRateLimited is matched by 429 responses.
+	ErrRateLimited = errors.New("hb: rate limited")
+	// ErrServer is matched by responses with a 5xx status code.
+	ErrServer = errors.New("hb: server error")
+)
+
+// ErrorResponse represents a Hummingbird API error response. It can be
+// compared with ErrNotFound, ErrUnauthorized, ErrRateLimited and ErrServer
+// using errors.Is:
+//
+//	_, _, err := c.Anime.Get("unknown-anime", "")
+//	if errors.Is(err, hb.ErrNotFound) {
+//		// handle missing anime
+//	}
 type ErrorResponse struct {
 	Response *http.Response
 	Message  string `json:"error"`
+
+	// Method and URL of the request that caused the error.
+	Method string   `json:"-"`
+	URL    *url.URL `json:"-"`
+
+	// Body is the raw body of the API response.
+	Body []byte `json:"-"`
 }
 
 func (r *ErrorResponse) Error() string {
-	return fmt.Sprintf("%v %v: %v %v", r.Response.Request.Method, r.Response.Request.URL,
-		r.Response.StatusCode, r.Message)
+	method, u := r.Method, r.URL
+	if u == nil && r.Response.Request != nil {
+		method, u = r.Response.Request.Method, r.Response.Request.URL
+	}
+	return fmt.Sprintf("%v %v: %v %v", method, u, r.Response.StatusCode, r.Message)
 }
+
+// Unwrap returns the error among ErrNotFound, ErrUnauthorized, ErrRateLimited
+// and ErrServer that matches the status code of the response, or nil if none
+// does.
+func (r *ErrorResponse) Unwrap() error {
+	switch c := r.Response.StatusCode; {
+	case c == http.StatusNotFound:
+		return ErrNotFound
+	case c == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case c == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case 500 <= c && c <= 599:
+		return ErrServer
+	}
+	return nil
+}
+
+// Bool returns a pointer to v, for use with the optional fields of Entry.
+func Bool(v bool) *bool { return &v }
+
+// Int returns a pointer to v, for use with the optional fields of Entry.
+func Int(v int) *int { return &v }
+
+// String returns a pointer to v, for use with the optional fields of Entry.
+func String(v string) *string { return &v }
@@ -1,6 +1,7 @@
 package hb
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -199,3 +200,39 @@ func TestClient_Do_connectionRefused(t *testing.T) {
 		t.Error("Expected connection refused error.")
 	}
 }
+
+func TestClient_DoContext_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.DoContext(ctx, req, nil)
+	if got, want := err, context.Canceled; got != want {
+		t.Errorf("DoContext with canceled context returned error %v, want %v", got, want)
+	}
+}
+
+func TestClient_Do_requestContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req.WithContext(ctx), nil)
+	if got, want := err, context.Canceled; got != want {
+		t.Errorf("Do with canceled request context returned error %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package hbtest
+
+import (
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// DefaultFixtures returns a small set of anime and a user named "cybrox" with
+// the password "password" whose library has an entry for each status.
+func DefaultFixtures() Fixtures {
+	logHorizon := hb.Anime{
+		ID:             7622,
+		MALID:          17265,
+		Slug:           "log-horizon",
+		Status:         "Finished Airing",
+		Title:          "Log Horizon",
+		EpisodeCount:   25,
+		EpisodeLength:  25,
+		ShowType:       "TV",
+		StartedAiring:  date("2013-10-05"),
+		FinishedAiring: date("2014-03-22"),
+		AgeRating:      "PG13",
+		Genres:         []hb.Genre{{Name: "Action"}, {Name: "Adventure"}, {Name: "Fantasy"}},
+	}
+	nichijou := hb.Anime{
+		ID:             5766,
+		MALID:          10165,
+		Slug:           "nichijou",
+		Status:         "Finished Airing",
+		Title:          "Nichijou",
+		AlternateTitle: "My Ordinary Life",
+		EpisodeCount:   26,
+		EpisodeLength:  24,
+		ShowType:       "TV",
+		StartedAiring:  date("2011-04-03"),
+		FinishedAiring: date("2011-09-25"),
+		AgeRating:      "PG13",
+		Genres:         []hb.Genre{{Name: "Comedy"}, {Name: "School"}, {Name: "Slice of Life"}},
+	}
+	anohana := hb.Anime{
+		ID:             5874,
+		MALID:          9989,
+		Slug:           "anohana",
+		Status:         "Finished Airing",
+		Title:          "Anohana: The Flower We Saw That Day",
+		EpisodeCount:   11,
+		EpisodeLength:  23,
+		ShowType:       "TV",
+		StartedAiring:  date("2011-04-15"),
+		FinishedAiring: date("2011-06-24"),
+		AgeRating:      "PG13",
+		Genres:         []hb.Genre{{Name: "Drama"}, {Name: "Slice of Life"}},
+	}
+	cowboyBebop := hb.Anime{
+		ID:             1,
+		MALID:          1,
+		Slug:           "cowboy-bebop",
+		Status:         "Finished Airing",
+		Title:          "Cowboy Bebop",
+		EpisodeCount:   26,
+		EpisodeLength:  24,
+		ShowType:       "TV",
+		StartedAiring:  date("1998-04-03"),
+		FinishedAiring: date("1999-04-24"),
+		AgeRating:      "R17+",
+		Genres:         []hb.Genre{{Name: "Action"}, {Name: "Sci-Fi"}},
+	}
+	kiznaiver := hb.Anime{
+		ID:            11253,
+		MALID:         31798,
+		Slug:          "kiznaiver",
+		Status:        "Currently Airing",
+		Title:         "Kiznaiver",
+		EpisodeCount:  12,
+		EpisodeLength: 24,
+		ShowType:      "TV",
+		StartedAiring: date("2016-04-10"),
+		AgeRating:     "PG13",
+		Genres:        []hb.Genre{{Name: "Drama"}, {Name: "Sci-Fi"}},
+	}
+
+	updated := time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)
+	return Fixtures{
+		Anime: []hb.Anime{logHorizon, nichijou, anohana, cowboyBebop, kiznaiver},
+		Users: []User{
+			{
+				User: hb.User{
+					Name:                    "cybrox",
+					About:                   "Hummingbird developer.",
+					TitleLanguagePreference: "canonical",
+					LastLibraryUpdate:       &hb.Date{Time: updated},
+				},
+				Email:    "cybrox@example.com",
+				Password: "password",
+				Library: []hb.LibraryEntry{
+					{
+						EpisodesWatched: 25,
+						UpdatedAt:       &updated,
+						Status:          hb.StatusCompleted,
+						Anime:           &logHorizon,
+						Rating:          &hb.LibraryEntryRating{Type: "advanced", Value: "4.5"},
+					},
+					{
+						EpisodesWatched: 12,
+						UpdatedAt:       &updated,
+						Status:          hb.StatusCurrentlyWatching,
+						Anime:           &nichijou,
+						Rating:          &hb.LibraryEntryRating{Type: "advanced", Value: "4.0"},
+					},
+					{
+						UpdatedAt: &updated,
+						Status:    hb.StatusPlanToWatch,
+						Anime:     &anohana,
+					},
+					{
+						EpisodesWatched: 10,
+						UpdatedAt:       &updated,
+						Status:          hb.StatusOnHold,
+						Anime:           &cowboyBebop,
+					},
+					{
+						EpisodesWatched: 2,
+						UpdatedAt:       &updated,
+						Status:          hb.StatusDropped,
+						Anime:           &kiznaiver,
+						Rating:          &hb.LibraryEntryRating{Type: "advanced", Value: "2.0"},
+					},
+				},
+				FavoriteAnime: []int{logHorizon.ID, nichijou.ID},
+			},
+		},
+	}
+}
+
+// date returns the date of a fixture, given as "2006-01-02".
+func date(s string) *hb.Date {
+	d, err := hb.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return &d
+}
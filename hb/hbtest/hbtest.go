@@ -0,0 +1,740 @@
+// Package hbtest provides an in-memory fake of the Hummingbird API v1 for
+// testing code that uses package hb without accessing the network.
+//
+// A Server keeps users, anime, libraries, feeds and favorites in memory and
+// updates them when the library methods of the API are called. It can be
+// seeded with fixtures and can inject faults such as latency, server errors
+// and malformed JSON responses:
+//
+//	srv := hbtest.NewServer()
+//	defer srv.Close()
+//	srv.Seed(hbtest.DefaultFixtures())
+//
+//	c := srv.Client()
+//	entries, _, err := c.User.Library("cybrox", hb.StatusCurrentlyWatching)
+package hbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// User is a user of the fake API along with the data that belongs to them.
+type User struct {
+	hb.User
+
+	// Email and Password are the credentials that authenticate the user.
+	// Either Name or Email can be used along with Password.
+	Email    string
+	Password string
+
+	// Library holds the library entries of the user. The Anime of each
+	// entry must have an ID.
+	Library []hb.LibraryEntry
+
+	// Feed holds the stories of the activity feed of the user, newest
+	// first.
+	Feed []hb.Story
+
+	// FavoriteAnime holds the IDs of the favorite anime of the user in the
+	// order of their rank.
+	FavoriteAnime []int
+}
+
+// Fixtures are the data a Server is seeded with.
+type Fixtures struct {
+	Anime []hb.Anime
+	Users []User
+}
+
+// Fault describes a failure the Server injects in its responses.
+type Fault struct {
+	// Path limits the fault to requests whose path starts with it, such as
+	// "/api/v1/anime/". If empty, the fault applies to all requests.
+	Path string
+
+	// Times is the number of requests the fault applies to. If zero, it
+	// applies to all matching requests until the faults are cleared.
+	Times int
+
+	// Latency delays the response.
+	Latency time.Duration
+
+	// StatusCode, if set, makes the server respond with this status and a
+	// JSON error instead of handling the request.
+	StatusCode int
+
+	// MalformedJSON makes the server respond with a body that is not valid
+	// JSON instead of handling the request.
+	MalformedJSON bool
+}
+
+// Server is a fake Hummingbird API v1 server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Now returns the current time which is used for the timestamps of
+	// library updates and stories. It defaults to time.Now.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	anime    map[int]*hb.Anime
+	slugs    map[string]int
+	users    map[string]*user
+	tokens   map[string]string
+	faults   []*Fault
+	nextID   int
+	requests int
+}
+
+// user is the state of a User kept by the server.
+type user struct {
+	User
+	library map[int]*hb.LibraryEntry
+}
+
+// NewServer starts and returns a new Server with no data. The caller should
+// call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Now:    time.Now,
+		anime:  make(map[int]*hb.Anime),
+		slugs:  make(map[string]int),
+		users:  make(map[string]*user),
+		tokens: make(map[string]string),
+		nextID: 1,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a hb.Client that sends its requests to the server.
+func (s *Server) Client() *hb.Client {
+	c := hb.NewClient(s.Server.Client())
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+	return c
+}
+
+// Seed adds the anime and users of f to the server. Existing anime and users
+// with the same ID or name are replaced.
+func (s *Server) Seed(f Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range f.Anime {
+		a := f.Anime[i]
+		s.anime[a.ID] = &a
+		if a.Slug != "" {
+			s.slugs[a.Slug] = a.ID
+		}
+	}
+	for _, u := range f.Users {
+		st := &user{User: u, library: make(map[int]*hb.LibraryEntry)}
+		st.User.Library = nil
+		for i := range u.Library {
+			e := copyEntry(&u.Library[i])
+			if e.ID == 0 {
+				e.ID = s.newID()
+			}
+			if e.Anime != nil {
+				st.library[e.Anime.ID] = e
+			}
+		}
+		s.users[u.Name] = st
+	}
+}
+
+// Token returns an authentication token for the user as if they had
+// authenticated.
+func (s *Server) Token(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken(username)
+}
+
+// RevokeTokens invalidates all the authentication tokens of the user so that
+// the API rejects them like expired tokens.
+func (s *Server) RevokeTokens(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, name := range s.tokens {
+		if name == username {
+			delete(s.tokens, token)
+		}
+	}
+}
+
+// Library returns the current library entries of the user sorted by anime ID.
+func (s *Server) Library(username string) []hb.LibraryEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		return nil
+	}
+	return u.entries("")
+}
+
+// Feed returns the current activity feed of the user.
+func (s *Server) Feed(username string) []hb.Story {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		return nil
+	}
+	return append([]hb.Story(nil), u.Feed...)
+}
+
+// AddStory adds a story at the top of the activity feed of the user. If the
+// story or its substories have no ID, new ones are assigned.
+func (s *Server) AddStory(username string, story hb.Story) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		return
+	}
+	s.addStory(u, story)
+}
+
+// InjectFault makes the server fail according to f. Faults are matched in the
+// order they were injected.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all the injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the number of requests the server has received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// fault returns the first fault that matches r, consuming one of its times.
+func (s *Server) fault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		ff := *f
+		return &ff
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	if f := s.fault(r); f != nil {
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		switch {
+		case f.StatusCode != 0:
+			writeError(w, f.StatusCode, http.StatusText(f.StatusCode))
+			return
+		case f.MalformedJSON:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"error":`)
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "api" || parts[1] != "v1" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	parts = parts[2:]
+
+	switch {
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "anime":
+		s.getAnime(w, r, parts[1])
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "search" && parts[1] == "anime":
+		s.searchAnime(w, r)
+	case r.Method == "POST" && len(parts) == 2 && parts[0] == "users" && parts[1] == "authenticate":
+		s.authenticate(w, r)
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "users":
+		s.getUser(w, r, parts[1])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "users" && parts[2] == "feed":
+		s.getFeed(w, r, parts[1])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "users" && parts[2] == "favorite_anime":
+		s.getFavoriteAnime(w, r, parts[1])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "users" && parts[2] == "library":
+		s.getLibrary(w, r, parts[1])
+	case r.Method == "POST" && len(parts) == 2 && parts[0] == "libraries":
+		s.updateLibrary(w, r, parts[1])
+	case r.Method == "POST" && len(parts) == 3 && parts[0] == "libraries" && parts[2] == "remove":
+		s.removeLibrary(w, r, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// issueToken must be called with s.mu held.
+func (s *Server) issueToken(username string) string {
+	token := fmt.Sprintf("token-%s-%d", username, s.newID())
+	s.tokens[token] = username
+	return token
+}
+
+// newID must be called with s.mu held.
+func (s *Server) newID() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// findAnime returns the anime with an ID or slug. It must be called with s.mu
+// held.
+func (s *Server) findAnime(idOrSlug string) (*hb.Anime, bool) {
+	id, err := strconv.Atoi(idOrSlug)
+	if err != nil {
+		id = s.slugs[idOrSlug]
+	}
+	a, ok := s.anime[id]
+	return a, ok
+}
+
+// withoutGenres returns a copy of a without genres, as returned by the API
+// for searches and library entries.
+func withoutGenres(a *hb.Anime) *hb.Anime {
+	aa := *a
+	aa.Genres = nil
+	return &aa
+}
+
+// copyEntry returns a copy of e that shares none of its pointer fields, so
+// that changing one doesn't change the other.
+func copyEntry(e *hb.LibraryEntry) *hb.LibraryEntry {
+	c := *e
+	if e.LastWatched != nil {
+		d := *e.LastWatched
+		c.LastWatched = &d
+	}
+	if e.UpdatedAt != nil {
+		t := *e.UpdatedAt
+		c.UpdatedAt = &t
+	}
+	if e.Anime != nil {
+		a := *e.Anime
+		c.Anime = &a
+	}
+	if e.Rating != nil {
+		r := *e.Rating
+		c.Rating = &r
+	}
+	return &c
+}
+
+func (s *Server) getAnime(w http.ResponseWriter, r *http.Request, idOrSlug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.findAnime(idOrSlug)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Couldn't find Anime")
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (s *Server) searchAnime(w http.ResponseWriter, r *http.Request) {
+	q := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("query")))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int, 0, len(s.anime))
+	for id := range s.anime {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	results := []*hb.Anime{}
+	for _, id := range ids {
+		a := s.anime[id]
+		if q == "" || !strings.Contains(strings.ToLower(a.Title), q) &&
+			!strings.Contains(strings.ToLower(a.AlternateTitle), q) &&
+			!strings.Contains(a.Slug, q) {
+			continue
+		}
+		results = append(results, withoutGenres(a))
+		if len(results) == 5 {
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) {
+	var a struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, u := range s.users {
+		if (a.Username != "" && a.Username == name || a.Email != "" && a.Email == u.Email) &&
+			a.Password == u.Password {
+			writeJSON(w, http.StatusCreated, s.issueToken(name))
+			return
+		}
+	}
+	writeError(w, http.StatusUnauthorized, "Invalid credentials")
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Couldn't find User")
+		return
+	}
+	writeJSON(w, http.StatusOK, u.User.User)
+}
+
+func (s *Server) getFeed(w http.ResponseWriter, r *http.Request, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Couldn't find User")
+		return
+	}
+	feed := u.Feed
+	if feed == nil {
+		feed = []hb.Story{}
+	}
+	writeJSON(w, http.StatusOK, feed)
+}
+
+func (s *Server) getFavoriteAnime(w http.ResponseWriter, r *http.Request, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Couldn't find User")
+		return
+	}
+	favorites := []hb.Anime{}
+	for i, id := range u.FavoriteAnime {
+		a, ok := s.anime[id]
+		if !ok {
+			continue
+		}
+		fav := *a
+		fav.FavID = id
+		fav.FavRank = i + 1
+		favorites = append(favorites, fav)
+	}
+	writeJSON(w, http.StatusOK, favorites)
+}
+
+func (s *Server) getLibrary(w http.ResponseWriter, r *http.Request, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[username]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Couldn't find User")
+		return
+	}
+	writeJSON(w, http.StatusOK, u.entries(r.URL.Query().Get("status")))
+}
+
+// entries returns the library entries of u with the given status, or all of
+// them if status is empty, sorted by anime ID.
+func (u *user) entries(status string) []hb.LibraryEntry {
+	ids := make([]int, 0, len(u.library))
+	for id := range u.library {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	entries := []hb.LibraryEntry{}
+	for _, id := range ids {
+		e := copyEntry(u.library[id])
+		if status != "" && string(e.Status) != status {
+			continue
+		}
+		if e.Anime != nil {
+			e.Anime = withoutGenres(e.Anime)
+		}
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+// entryRequest is the body of the library requests. Pointers tell apart the
+// fields that were not sent.
+type entryRequest struct {
+	ID                string  `json:"id"`
+	AuthToken         string  `json:"auth_token"`
+	Status            *string `json:"status"`
+	Privacy           *string `json:"privacy"`
+	Rating            *string `json:"rating"`
+	SaneRatingUpdate  *string `json:"sane_rating_update"`
+	Rewatching        *bool   `json:"rewatching"`
+	RewatchedTimes    *int    `json:"rewatched_times"`
+	Notes             *string `json:"notes"`
+	EpisodesWatched   *int    `json:"episodes_watched"`
+	IncrementEpisodes bool    `json:"increment_episodes"`
+}
+
+// authorize decodes the body of a library request and returns the user that
+// its token belongs to. It must be called with s.mu held.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) (*entryRequest, *user, bool) {
+	req := new(entryRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return nil, nil, false
+	}
+	username, ok := s.tokens[req.AuthToken]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Invalid authentication token")
+		return nil, nil, false
+	}
+	u, ok := s.users[username]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Invalid authentication token")
+		return nil, nil, false
+	}
+	return req, u, true
+}
+
+func (s *Server) updateLibrary(w http.ResponseWriter, r *http.Request, idOrSlug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req, u, ok := s.authorize(w, r)
+	if !ok {
+		return
+	}
+	a, ok := s.findAnime(idOrSlug)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Couldn't find Anime")
+		return
+	}
+
+	now := s.Now()
+	old, exists := u.library[a.ID]
+	if !exists {
+		// Like the real API, adding an entry without a status succeeds
+		// without adding anything.
+		if req.Status == nil {
+			writeJSON(w, http.StatusCreated, true)
+			return
+		}
+		old = &hb.LibraryEntry{Anime: withoutGenres(a)}
+	}
+	oldStatus, oldEpisodes := old.Status, old.EpisodesWatched
+
+	// The request is applied to a copy of the entry so that an invalid
+	// request leaves the library as it was.
+	e := copyEntry(old)
+	if err := applyEntry(e, req, a); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if !exists {
+		e.ID = s.newID()
+	}
+	e.UpdatedAt = &now
+
+	var substories []hb.Substory
+	if e.Status != oldStatus {
+		substories = append(substories, hb.Substory{
+			SubstoryType: hb.SubstoryStatusUpdate,
+			CreatedAt:    &now,
+			NewStatus:    e.Status.String(),
+		})
+	}
+	if e.EpisodesWatched > oldEpisodes {
+		e.LastWatched = &hb.Date{Time: now}
+		substories = append(substories, hb.Substory{
+			SubstoryType:  hb.SubstoryWatchedEpisode,
+			CreatedAt:     &now,
+			EpisodeNumber: strconv.Itoa(e.EpisodesWatched),
+		})
+	}
+	if len(substories) > 0 {
+		s.addStory(u, hb.Story{
+			StoryType:  hb.StoryMedia,
+			User:       &hb.UserMini{Name: u.Name},
+			UpdatedAt:  &now,
+			Media:      withoutGenres(a),
+			Substories: substories,
+		})
+	}
+
+	u.library[a.ID] = e
+
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, e)
+}
+
+// applyEntry applies the values of a library request to an entry.
+func applyEntry(e *hb.LibraryEntry, req *entryRequest, a *hb.Anime) error {
+	if req.Status != nil && *req.Status != "" {
+		status := hb.LibraryStatus(*req.Status)
+		if !status.Valid() {
+			return fmt.Errorf("invalid status %q", *req.Status)
+		}
+		e.Status = status
+	}
+	if req.Privacy != nil {
+		switch *req.Privacy {
+		case "public":
+			e.Private = false
+		case "private":
+			e.Private = true
+		default:
+			return fmt.Errorf("invalid privacy %q", *req.Privacy)
+		}
+	}
+	if req.Rating != nil {
+		r, err := parseRating(*req.Rating)
+		if err != nil {
+			return err
+		}
+		if cur := e.Rating; r == 0 || cur != nil && sameRating(cur, r) {
+			e.Rating = nil
+		} else {
+			e.Rating = advancedRating(r)
+		}
+	}
+	if req.SaneRatingUpdate != nil {
+		r, err := parseRating(*req.SaneRatingUpdate)
+		if err != nil {
+			return err
+		}
+		e.Rating = nil
+		if r != 0 {
+			e.Rating = advancedRating(r)
+		}
+	}
+	if req.Rewatching != nil {
+		e.Rewatching = *req.Rewatching
+	}
+	if req.RewatchedTimes != nil {
+		if *req.RewatchedTimes < 0 {
+			return fmt.Errorf("invalid rewatched times %d", *req.RewatchedTimes)
+		}
+		e.RewatchedTimes = *req.RewatchedTimes
+	}
+	if req.Notes != nil {
+		e.Notes = *req.Notes
+		e.NotesPresent = e.Notes != ""
+	}
+	if req.EpisodesWatched != nil {
+		e.EpisodesWatched = *req.EpisodesWatched
+	}
+	if req.IncrementEpisodes {
+		e.EpisodesWatched++
+	}
+	if e.EpisodesWatched < 0 || a.EpisodeCount > 0 && e.EpisodesWatched > a.EpisodeCount {
+		return fmt.Errorf("invalid episodes watched %d", e.EpisodesWatched)
+	}
+	return nil
+}
+
+// parseRating parses a rating the way the API accepts it, a number from 0 to
+// 5 in steps of 0.5.
+func parseRating(value string) (hb.Rating, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if r := hb.Rating(f); err != nil || !r.Valid() {
+		return 0, fmt.Errorf("invalid rating %q", value)
+	}
+	return hb.Rating(f), nil
+}
+
+// sameRating reports whether the rating of a library entry is r.
+func sameRating(cur *hb.LibraryEntryRating, r hb.Rating) bool {
+	v, err := cur.Rating()
+	return err == nil && v == r
+}
+
+// advancedRating returns r the way the API returns it, with one decimal.
+func advancedRating(r hb.Rating) *hb.LibraryEntryRating {
+	return &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: strconv.FormatFloat(float64(r), 'f', 1, 64)}
+}
+
+func (s *Server) removeLibrary(w http.ResponseWriter, r *http.Request, idOrSlug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, u, ok := s.authorize(w, r)
+	if !ok {
+		return
+	}
+	a, ok := s.findAnime(idOrSlug)
+	if !ok {
+		writeJSON(w, http.StatusOK, false)
+		return
+	}
+	_, exists := u.library[a.ID]
+	delete(u.library, a.ID)
+	writeJSON(w, http.StatusOK, exists)
+}
+
+// addStory must be called with s.mu held.
+func (s *Server) addStory(u *user, story hb.Story) {
+	if story.ID == 0 {
+		story.ID = s.newID()
+	}
+	for i := range story.Substories {
+		if story.Substories[i].ID == 0 {
+			story.Substories[i].ID = s.newID()
+		}
+	}
+	story.SubstoriesCount = len(story.Substories)
+	u.Feed = append([]hb.Story{story}, u.Feed...)
+}
@@ -0,0 +1,227 @@
+package hbtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func newTestServer() (*Server, *hb.Client) {
+	srv := NewServer()
+	srv.Seed(DefaultFixtures())
+	return srv, srv.Client()
+}
+
+func TestServer_anime(t *testing.T) {
+	srv, c := newTestServer()
+	defer srv.Close()
+
+	anime, _, err := c.Anime.Get("log-horizon", "")
+	if err != nil {
+		t.Fatalf("Anime.Get returned error %v", err)
+	}
+	if got, want := anime.ID, 7622; got != want {
+		t.Errorf("Anime.Get ID is %v, want %v", got, want)
+	}
+	if len(anime.Genres) == 0 {
+		t.Error("Anime.Get returned anime without genres.")
+	}
+
+	_, _, err = c.Anime.Get("unknown", "")
+	if !errors.Is(err, hb.ErrNotFound) {
+		t.Errorf("Anime.Get unknown anime returned error %v, want %v", err, hb.ErrNotFound)
+	}
+
+	results, _, err := c.Anime.Search("ordinary")
+	if err != nil {
+		t.Fatalf("Anime.Search returned error %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Nichijou" {
+		t.Errorf("Anime.Search returned %+v, want Nichijou", results)
+	}
+	if len(results[0].Genres) != 0 {
+		t.Error("Anime.Search returned anime with genres.")
+	}
+}
+
+func TestServer_users(t *testing.T) {
+	srv, c := newTestServer()
+	defer srv.Close()
+
+	u, _, err := c.User.Get("cybrox")
+	if err != nil {
+		t.Fatalf("User.Get returned error %v", err)
+	}
+	if got, want := u.Name, "cybrox"; got != want {
+		t.Errorf("User.Get name is %v, want %v", got, want)
+	}
+
+	favorites, _, err := c.User.FavoriteAnime("cybrox")
+	if err != nil {
+		t.Fatalf("User.FavoriteAnime returned error %v", err)
+	}
+	if len(favorites) != 2 || favorites[0].Title != "Log Horizon" || favorites[1].FavRank != 2 {
+		t.Errorf("User.FavoriteAnime returned %+v", favorites)
+	}
+
+	entries, _, err := c.User.Library("cybrox", hb.StatusCurrentlyWatching)
+	if err != nil {
+		t.Fatalf("User.Library returned error %v", err)
+	}
+	if len(entries) != 1 || entries[0].Anime.Title != "Nichijou" {
+		t.Errorf("User.Library returned %+v", entries)
+	}
+
+	all, _, _ := c.User.Library("cybrox", "")
+	if got, want := len(all), 5; got != want {
+		t.Errorf("User.Library returned %v entries, want %v", got, want)
+	}
+}
+
+func TestServer_libraryUpdate(t *testing.T) {
+	srv, c := newTestServer()
+	defer srv.Close()
+
+	token, _, err := c.User.Authenticate("", "cybrox@example.com", "password")
+	if err != nil {
+		t.Fatalf("User.Authenticate returned error %v", err)
+	}
+
+	e, _, err := c.Library.Update("nichijou", token, &hb.Entry{IncrementEpisodes: true, SaneRatingUpdate: hb.NewRating(5)})
+	if err != nil {
+		t.Fatalf("Library.Update returned error %v", err)
+	}
+	if got, want := e.EpisodesWatched, 13; got != want {
+		t.Errorf("Library.Update episodes watched is %v, want %v", got, want)
+	}
+	if got, want := e.Rating.Value, "5.0"; got != want {
+		t.Errorf("Library.Update rating is %v, want %v", got, want)
+	}
+
+	feed, _, err := c.User.Feed("cybrox")
+	if err != nil {
+		t.Fatalf("User.Feed returned error %v", err)
+	}
+	if len(feed) != 1 || feed[0].Substories[0].EpisodeNumber != "13" {
+		t.Errorf("User.Feed returned %+v, want a watched episode story", feed)
+	}
+
+	removed, _, err := c.Library.Remove("anohana", token)
+	if err != nil || !removed {
+		t.Errorf("Library.Remove returned %v, %v, want true, nil", removed, err)
+	}
+	if got, want := len(srv.Library("cybrox")), 4; got != want {
+		t.Errorf("library has %v entries, want %v", got, want)
+	}
+}
+
+func TestServer_invalidToken(t *testing.T) {
+	srv, c := newTestServer()
+	defer srv.Close()
+
+	token := srv.Token("cybrox")
+	srv.RevokeTokens("cybrox")
+
+	_, _, err := c.Library.Update("nichijou", token, nil)
+	if !errors.Is(err, hb.ErrUnauthorized) {
+		t.Errorf("Library.Update returned error %v, want %v", err, hb.ErrUnauthorized)
+	}
+
+	_, _, err = c.User.Authenticate("cybrox", "", "wrong")
+	if !errors.Is(err, hb.ErrUnauthorized) {
+		t.Errorf("User.Authenticate returned error %v, want %v", err, hb.ErrUnauthorized)
+	}
+}
+
+func TestServer_faults(t *testing.T) {
+	srv, c := newTestServer()
+	defer srv.Close()
+
+	srv.InjectFault(Fault{Path: "/api/v1/anime/", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if _, _, err := c.Anime.Get("nichijou", ""); !errors.Is(err, hb.ErrServer) {
+		t.Errorf("Anime.Get returned error %v, want %v", err, hb.ErrServer)
+	}
+	if _, _, err := c.Anime.Get("nichijou", ""); err != nil {
+		t.Errorf("Anime.Get after fault returned error %v", err)
+	}
+
+	srv.InjectFault(Fault{MalformedJSON: true})
+	if _, _, err := c.User.Get("cybrox"); err == nil {
+		t.Error("Expected JSON decode error.")
+	}
+	srv.ClearFaults()
+
+	srv.InjectFault(Fault{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.User.GetContext(ctx, "cybrox"); err != context.DeadlineExceeded {
+		t.Errorf("User.GetContext returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestServer_libraryUpdate_invalid(t *testing.T) {
+	srv, c := newTestServer()
+	defer srv.Close()
+
+	token := srv.Token("cybrox")
+	before := srv.Library("cybrox")
+
+	// The status is valid but the episodes watched are not, so nothing must
+	// change.
+	_, _, err := c.Library.Update("nichijou", token, &hb.Entry{Status: hb.StatusCompleted, EpisodesWatched: hb.Int(100)})
+	if err == nil {
+		t.Error("Library.Update with invalid episodes watched returned no error.")
+	}
+	if got := srv.Library("cybrox"); !reflect.DeepEqual(got, before) {
+		t.Errorf("library after invalid update is %+v, want %+v", got, before)
+	}
+
+	if _, _, err := c.Library.Remove("anohana", token); err != nil {
+		t.Fatalf("Library.Remove returned error %v", err)
+	}
+	before = srv.Library("cybrox")
+	_, _, err = c.Library.Update("anohana", token, &hb.Entry{Status: hb.StatusCompleted, EpisodesWatched: hb.Int(100)})
+	if err == nil {
+		t.Error("Library.Update of new entry with invalid episodes watched returned no error.")
+	}
+	if got := srv.Library("cybrox"); !reflect.DeepEqual(got, before) {
+		t.Errorf("library after invalid insert is %+v, want %+v", got, before)
+	}
+}
+
+func TestServer_Library_copy(t *testing.T) {
+	srv, _ := newTestServer()
+	defer srv.Close()
+
+	nichijou := func() *hb.LibraryEntry {
+		lib := srv.Library("cybrox")
+		for i := range lib {
+			if lib[i].Anime.Slug == "nichijou" {
+				return &lib[i]
+			}
+		}
+		t.Fatal("library has no nichijou entry")
+		return nil
+	}
+
+	e := nichijou()
+	e.Rating.Value = "1.0"
+	*e.UpdatedAt = time.Time{}
+	e.Anime.Title = "changed"
+
+	e = nichijou()
+	if got, want := e.Rating.Value, "4.0"; got != want {
+		t.Errorf("library entry rating after changing the returned entry is %v, want %v", got, want)
+	}
+	if e.UpdatedAt.IsZero() {
+		t.Error("library entry updated at changed with the returned entry")
+	}
+	if e.Anime.Title == "changed" {
+		t.Error("library entry anime changed with the returned entry")
+	}
+}
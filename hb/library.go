@@ -1,52 +1,137 @@
 package hb
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// LibraryStatus is the status of a library entry.
+type LibraryStatus string
+
 // Library entry statuses.
 const (
-	StatusCurrentlyWatching = "currently-watching"
-	StatusPlanToWatch       = "plan-to-watch"
-	StatusCompleted         = "completed"
-	StatusOnHold            = "on-hold"
-	StatusDropped           = "dropped"
+	StatusCurrentlyWatching LibraryStatus = "currently-watching"
+	StatusPlanToWatch       LibraryStatus = "plan-to-watch"
+	StatusCompleted         LibraryStatus = "completed"
+	StatusOnHold            LibraryStatus = "on-hold"
+	StatusDropped           LibraryStatus = "dropped"
 )
 
+// libraryStatusNames maps the human friendly names of the statuses, in lower
+// case and with spaces instead of dashes and underscores, to the statuses.
+var libraryStatusNames = map[string]LibraryStatus{
+	"currently watching": StatusCurrentlyWatching,
+	"watching":           StatusCurrentlyWatching,
+	"current":            StatusCurrentlyWatching,
+	"plan to watch":      StatusPlanToWatch,
+	"planned":            StatusPlanToWatch,
+	"ptw":                StatusPlanToWatch,
+	"completed":          StatusCompleted,
+	"complete":           StatusCompleted,
+	"on hold":            StatusOnHold,
+	"onhold":             StatusOnHold,
+	"dropped":            StatusDropped,
+}
+
+// ParseLibraryStatus parses a library status from either its API form, such
+// as "plan-to-watch", or a human friendly form such as "Plan to Watch",
+// "watching" or "on hold". It is case insensitive.
+func ParseLibraryStatus(s string) (LibraryStatus, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	if status, ok := libraryStatusNames[name]; ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("hb: invalid library status %q", s)
+}
+
+// Valid reports whether s is one of the library statuses of the API.
+func (s LibraryStatus) Valid() bool {
+	switch s {
+	case StatusCurrentlyWatching, StatusPlanToWatch, StatusCompleted, StatusOnHold, StatusDropped:
+		return true
+	}
+	return false
+}
+
+// validate returns an error if s is neither empty nor valid.
+func (s LibraryStatus) validate() error {
+	if s != "" && !s.Valid() {
+		return fmt.Errorf("hb: invalid library status %q", string(s))
+	}
+	return nil
+}
+
+// String returns the API form of the status.
+func (s LibraryStatus) String() string {
+	return string(s)
+}
+
+// MarshalText implements encoding.TextMarshaler. Statuses that are not
+// valid are marshaled as they are, so that entries with statuses unknown to
+// this package can be encoded again. Requests are validated before they are
+// sent instead.
+func (s LibraryStatus) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the forms
+// accepted by ParseLibraryStatus.
+func (s *LibraryStatus) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = ""
+		return nil
+	}
+	status, err := ParseLibraryStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Like MarshalText, it marshals
+// statuses that are not valid as they are.
+func (s LibraryStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(s))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Statuses that are not known are
+// kept as they are, so that a status added to the API does not make the
+// whole response fail to decode, and can be detected with Valid.
+func (s *LibraryStatus) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	if status, err := ParseLibraryStatus(str); err == nil {
+		*s = status
+		return nil
+	}
+	*s = LibraryStatus(str)
+	return nil
+}
+
 // LibraryEntry represents a library entry of a Hummingbird user.
 type LibraryEntry struct {
 	ID              int                 `json:"id,omitempty"`
 	EpisodesWatched int                 `json:"episodes_watched,omitempty"`
-	LastWatched     *time.Time          `json:"last_watched,omitempty"`
+	LastWatched     *Date               `json:"last_watched,omitempty"`
 	UpdatedAt       *time.Time          `json:"updated_at,omitempty"`
 	RewatchedTimes  int                 `json:"rewatched_times,omitempty"`
 	Notes           string              `json:"notes,omitempty"`
 	NotesPresent    bool                `json:"notes_present,omitempty"`
-	Status          string              `json:"status,omitempty"`
+	Status          LibraryStatus       `json:"status,omitempty"`
 	Private         bool                `json:"private,omitempty"`
 	Rewatching      bool                `json:"rewatching,omitempty"`
 	Anime           *Anime              `json:"anime,omitempty"`
 	Rating          *LibraryEntryRating `json:"rating,omitempty"`
 }
 
-// LibraryEntryRating represents the rating of a user's library entry.
-// The representation it's value depends on the type which can be either
-// "simple" or "advanced".
-//
-// If type is "simple", the value can be "negative", "neutral" or "positive".
-// If type is "advanced", the value can be a number between "0.0" and "5.0".
-//
-// For conversion between "simple" and "advanced":
-//   0   <= "negative" => 2.4
-//   2.4 <  "neutral"   > 3.6
-//   3.6 <= "positive" => 5
-type LibraryEntryRating struct {
-	Type  string `json:"type,omitempty"`
-	Value string `json:"value,omitempty"`
-}
-
 // LibraryService handles communication with the Hummingbird API library
 // methods (GET /users/{username}/library} is handled by UserService).
 //
@@ -84,34 +169,38 @@ type LibraryService struct {
 //
 // Rating - Optional
 //
-// Can be one of:
-//   "0", "0.5", "1", "1.5", "2", "2.5", "3", "3.5", "4", "4.5", "5".
-// Setting it to the current value or "0" will remove the rating.
+// A rating from 0 to 5 in steps of 0.5, such as hb.NewRating(4.5).
+// Setting it to the current value or 0 will remove the rating.
 //
 // SaneRatingUpdate - Optional
 //
-// Can be one of:
-//   "0", "0.5", "1", "1.5", "2", "2.5", "3", "3.5", "4", "4.5", "5".
-// Setting it to "0" will remove the rating. This should be used instead of
+// A rating from 0 to 5 in steps of 0.5, such as hb.NewRating(4.5).
+// Setting it to 0 will remove the rating. This should be used instead of
 // Rating if you don't want to unset the rating when setting it to the value
 // it already has.
 //
 // Rewatching - Optional
 //
-// Can be true or false.
+// Can be true or false, such as hb.Bool(true).
 //
 // RewatchedTimes - Optional
 //
-// Number of rewatches. Can be 0 or above.
+// Number of rewatches. Can be 0 or above, such as hb.Int(2).
 //
 // Notes - Optional
 //
-// Personal notes.
+// Personal notes, such as hb.String("crazy"). An empty string removes the
+// notes.
 //
 // EpisodesWatched - Optional
 //
-// Number of watched episodes. Can be between 0 and the total number of episodes.
-// If equal to total number of episodes, Status should be set to "completed".
+// Number of watched episodes, such as hb.Int(5). Can be between 0 and the
+// total number of episodes. If equal to total number of episodes, Status
+// should be set to "completed".
+//
+// Rewatching, RewatchedTimes, Notes and EpisodesWatched are pointers so that
+// zero values, such as 0 episodes watched, are sent while nil fields are left
+// unchanged.
 //
 // IncrementEpisodes - Optional
 //
@@ -121,17 +210,17 @@ type LibraryService struct {
 // Hummingbird API docs:
 // https://github.com/hummingbird-me/hummingbird/wiki/API-v1-Methods#parameters-3
 type Entry struct {
-	ID                string `json:"id"`
-	AuthToken         string `json:"auth_token"`
-	Status            string `json:"status,omitempty"`
-	Privacy           string `json:"privacy,omitempty"`
-	Rating            string `json:"rating,omitempty"`
-	SaneRatingUpdate  string `json:"sane_rating_update,omitempty"`
-	Rewatching        bool   `json:"rewatching,omitempty"`
-	RewatchedTimes    int    `json:"rewatched_times,omitempty"`
-	Notes             string `json:"notes,omitempty"`
-	EpisodesWatched   int    `json:"episodes_watched,omitempty"`
-	IncrementEpisodes bool   `json:"increment_episodes,omitempty"`
+	ID                string        `json:"id"`
+	AuthToken         string        `json:"auth_token"`
+	Status            LibraryStatus `json:"status,omitempty"`
+	Privacy           string        `json:"privacy,omitempty"`
+	Rating            *Rating       `json:"rating,omitempty"`
+	SaneRatingUpdate  *Rating       `json:"sane_rating_update,omitempty"`
+	Rewatching        *bool         `json:"rewatching,omitempty"`
+	RewatchedTimes    *int          `json:"rewatched_times,omitempty"`
+	Notes             *string       `json:"notes,omitempty"`
+	EpisodesWatched   *int          `json:"episodes_watched,omitempty"`
+	IncrementEpisodes bool          `json:"increment_episodes,omitempty"`
 }
 
 // Update adds or updates a user's library entry. The updated library entry is
@@ -154,6 +243,11 @@ type Entry struct {
 // instead of the expected library entry response. For that reason if status is
 // not provided, the method will use "currently-watching" as the default status.
 func (s *LibraryService) Update(animeID, authToken string, entry *Entry) (*LibraryEntry, *http.Response, error) {
+	return s.UpdateContext(context.Background(), animeID, authToken, entry)
+}
+
+// UpdateContext is like Update but sends the request bound to ctx.
+func (s *LibraryService) UpdateContext(ctx context.Context, animeID, authToken string, entry *Entry) (*LibraryEntry, *http.Response, error) {
 	urlStr := fmt.Sprintf("api/v1/libraries/%v", animeID)
 
 	if entry == nil {
@@ -161,6 +255,16 @@ func (s *LibraryService) Update(animeID, authToken string, entry *Entry) (*Libra
 		entry.Status = StatusCurrentlyWatching
 	}
 
+	if err := entry.Status.validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := entry.Rating.validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := entry.SaneRatingUpdate.validate(); err != nil {
+		return nil, nil, err
+	}
+
 	entry.ID = animeID
 	entry.AuthToken = authToken
 
@@ -170,7 +274,7 @@ func (s *LibraryService) Update(animeID, authToken string, entry *Entry) (*Libra
 	}
 
 	libraryEntry := new(LibraryEntry)
-	resp, err := s.client.Do(req, libraryEntry)
+	resp, err := s.client.DoContext(withMethodName(ctx, "Library.Update"), req, libraryEntry)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -186,6 +290,11 @@ func (s *LibraryService) Update(animeID, authToken string, entry *Entry) (*Libra
 //   token, err := c.User.Authenticate("USER_HUMMINGBIRD_USERNAME", "", "USER_HUMMINGBIRD_PASSWORD")
 //   // handle err
 func (s *LibraryService) Remove(animeID, authToken string) (bool, *http.Response, error) {
+	return s.RemoveContext(context.Background(), animeID, authToken)
+}
+
+// RemoveContext is like Remove but sends the request bound to ctx.
+func (s *LibraryService) RemoveContext(ctx context.Context, animeID, authToken string) (bool, *http.Response, error) {
 	urlStr := fmt.Sprintf("api/v1/libraries/%v/remove", animeID)
 
 	entry := &Entry{ID: animeID, AuthToken: authToken}
@@ -195,7 +304,7 @@ func (s *LibraryService) Remove(animeID, authToken string) (bool, *http.Response
 	}
 
 	removed := false
-	resp, err := s.client.Do(req, &removed)
+	resp, err := s.client.DoContext(withMethodName(ctx, "Library.Remove"), req, &removed)
 	if err != nil {
 		return false, resp, err
 	}
@@ -1,6 +1,8 @@
 package hb
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -19,7 +21,7 @@ func TestLibraryService_Update(t *testing.T) {
 		fmt.Fprintf(w, `{"id":7622,"episodes_watched":4}`)
 	})
 
-	entry := &Entry{EpisodesWatched: 3, IncrementEpisodes: true}
+	entry := &Entry{EpisodesWatched: Int(3), IncrementEpisodes: true}
 
 	libraryEntry, _, err := client.Library.Update("log-horizon", "valid_user_token", entry)
 	if err != nil {
@@ -163,3 +165,106 @@ func TestLibraryService_Remove_badAnimeID(t *testing.T) {
 		t.Error("Expected nil HTTP response when NewRequest fails.")
 	}
 }
+
+func TestLibraryService_UpdateContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testResourceID(t, r, "log-horizon")
+		fmt.Fprintf(w, `{"id":7622,"status":"currently-watching"}`)
+	})
+
+	libraryEntry, _, err := client.Library.UpdateContext(context.Background(), "log-horizon", "valid_user_token", nil)
+	if err != nil {
+		t.Errorf("Library.UpdateContext returned error %v", err)
+	}
+
+	got, want := libraryEntry, &LibraryEntry{ID: 7622, Status: StatusCurrentlyWatching}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Library.UpdateContext libraryEntry is %v, want %v", got, want)
+	}
+}
+
+func TestParseLibraryStatus(t *testing.T) {
+	tests := []struct {
+		in   string
+		want LibraryStatus
+	}{
+		{"currently-watching", StatusCurrentlyWatching},
+		{"Currently Watching", StatusCurrentlyWatching},
+		{"watching", StatusCurrentlyWatching},
+		{"plan_to_watch", StatusPlanToWatch},
+		{"Plan to Watch", StatusPlanToWatch},
+		{"completed", StatusCompleted},
+		{"On Hold", StatusOnHold},
+		{"on-hold", StatusOnHold},
+		{" dropped ", StatusDropped},
+	}
+	for _, tt := range tests {
+		got, err := ParseLibraryStatus(tt.in)
+		if err != nil {
+			t.Errorf("ParseLibraryStatus(%q) returned error %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLibraryStatus(%q) is %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseLibraryStatus("currently-wathcing"); err == nil {
+		t.Error("Expected invalid library status error.")
+	}
+}
+
+func TestLibraryStatus_JSON(t *testing.T) {
+	var e LibraryEntry
+	if err := json.Unmarshal([]byte(`{"status":"on-hold"}`), &e); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	if got, want := e.Status, StatusOnHold; got != want {
+		t.Errorf("Unmarshal status is %v, want %v", got, want)
+	}
+
+	// Unknown statuses from the API are kept as they are.
+	if err := json.Unmarshal([]byte(`{"status":"rewatching"}`), &e); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	if e.Status != "rewatching" || e.Status.Valid() {
+		t.Errorf("Unmarshal unknown status is %q, valid %v", e.Status, e.Status.Valid())
+	}
+
+	// And are marshaled again as they are.
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal of unknown status returned error %v", err)
+	}
+	if got, want := string(b), `{"status":"rewatching"}`; got != want {
+		t.Errorf("Marshal of unknown status is %s, want %s", got, want)
+	}
+	if b, err := LibraryStatus("rewatching").MarshalText(); err != nil || string(b) != "rewatching" {
+		t.Errorf("MarshalText of unknown status is %s, %v, want rewatching, nil", b, err)
+	}
+
+	var s LibraryStatus
+	if err := s.UnmarshalText([]byte("Plan to Watch")); err != nil || s != StatusPlanToWatch {
+		t.Errorf("UnmarshalText returned %v, %v, want %v, nil", s, err, StatusPlanToWatch)
+	}
+}
+
+func TestLibraryService_Update_invalidStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request sent with invalid status.")
+	})
+
+	_, resp, err := client.Library.Update("log-horizon", "valid_user_token", &Entry{Status: "wathcing"})
+	if err == nil {
+		t.Error("Expected invalid library status error.")
+	}
+	if resp != nil {
+		t.Error("Expected nil HTTP response when status is invalid.")
+	}
+}
@@ -0,0 +1,325 @@
+package hb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// LibraryDiff holds the differences between two sets of library entries,
+// such as a local copy of a library (local) and the library returned by
+// UserService.Library (remote). Entries are matched by anime ID and entries
+// without an anime are ignored.
+type LibraryDiff struct {
+	// Added are the local entries whose anime is not in the remote library.
+	Added []LibraryEntry
+
+	// Removed are the remote entries whose anime is not in the local
+	// library.
+	Removed []LibraryEntry
+
+	// Changed are the entries that are in both libraries but differ.
+	Changed []EntryDiff
+}
+
+// EntryDiff describes how the local and remote entries of an anime differ.
+type EntryDiff struct {
+	AnimeID int
+	Local   LibraryEntry
+	Remote  LibraryEntry
+
+	// Fields are the JSON names of the fields that differ, among "status",
+	// "episodes_watched", "rating", "rewatched_times", "rewatching", "notes"
+	// and "private".
+	Fields []string
+}
+
+// DiffLibrary compares the local and remote sets of library entries. The
+// results are sorted by anime ID.
+func DiffLibrary(local, remote []LibraryEntry) *LibraryDiff {
+	localByID := entriesByAnimeID(local)
+	remoteByID := entriesByAnimeID(remote)
+
+	d := new(LibraryDiff)
+	for _, id := range sortedAnimeIDs(localByID) {
+		l := localByID[id]
+		r, ok := remoteByID[id]
+		if !ok {
+			d.Added = append(d.Added, l)
+			continue
+		}
+		if fields := diffEntries(l, r); len(fields) > 0 {
+			d.Changed = append(d.Changed, EntryDiff{AnimeID: id, Local: l, Remote: r, Fields: fields})
+		}
+	}
+	for _, id := range sortedAnimeIDs(remoteByID) {
+		if _, ok := localByID[id]; !ok {
+			d.Removed = append(d.Removed, remoteByID[id])
+		}
+	}
+	return d
+}
+
+func entriesByAnimeID(entries []LibraryEntry) map[int]LibraryEntry {
+	m := make(map[int]LibraryEntry, len(entries))
+	for _, e := range entries {
+		if e.Anime != nil {
+			m[e.Anime.ID] = e
+		}
+	}
+	return m
+}
+
+func sortedAnimeIDs(m map[int]LibraryEntry) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// diffEntries returns the JSON names of the fields that differ between a and
+// b.
+func diffEntries(a, b LibraryEntry) []string {
+	var fields []string
+	if a.Status != b.Status {
+		fields = append(fields, "status")
+	}
+	if a.EpisodesWatched != b.EpisodesWatched {
+		fields = append(fields, "episodes_watched")
+	}
+	if !sameRating(a.Rating, b.Rating) {
+		fields = append(fields, "rating")
+	}
+	if a.RewatchedTimes != b.RewatchedTimes {
+		fields = append(fields, "rewatched_times")
+	}
+	if a.Rewatching != b.Rewatching {
+		fields = append(fields, "rewatching")
+	}
+	if a.Notes != b.Notes {
+		fields = append(fields, "notes")
+	}
+	if a.Private != b.Private {
+		fields = append(fields, "private")
+	}
+	return fields
+}
+
+// sameRating reports whether two ratings have the same value. Advanced values
+// are compared as numbers so that "4" and "4.0" are the same.
+func sameRating(a, b *LibraryEntryRating) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ra, erra := a.Rating()
+	rb, errb := b.Rating()
+	if erra == nil && errb == nil && a.Type != RatingTypeSimple && b.Type != RatingTypeSimple {
+		return ra == rb
+	}
+	return a.Type == b.Type && a.Value == b.Value
+}
+
+// SyncDirection tells which side of a sync wins.
+type SyncDirection int
+
+// Sync directions.
+const (
+	// SyncSkip leaves both sides as they are.
+	SyncSkip SyncDirection = iota
+	// SyncPush makes the remote library match the local one.
+	SyncPush
+	// SyncPull makes the local library match the remote one.
+	SyncPull
+)
+
+// ConflictPolicy decides which side wins when an entry has been changed on
+// both sides.
+type ConflictPolicy func(d EntryDiff) SyncDirection
+
+// NewerWins is a ConflictPolicy under which the entry with the most recent
+// UpdatedAt wins. If only one of them has UpdatedAt set, it wins and if none
+// does, or they are equal, the entry is skipped.
+func NewerWins(d EntryDiff) SyncDirection {
+	l, r := d.Local.UpdatedAt, d.Remote.UpdatedAt
+	switch {
+	case l == nil && r == nil:
+		return SyncSkip
+	case r == nil || l != nil && l.After(*r):
+		return SyncPush
+	case l == nil || r.After(*l):
+		return SyncPull
+	}
+	return SyncSkip
+}
+
+// LocalWins is a ConflictPolicy under which the local entry always wins.
+func LocalWins(d EntryDiff) SyncDirection { return SyncPush }
+
+// RemoteWins is a ConflictPolicy under which the remote entry always wins.
+func RemoteWins(d EntryDiff) SyncDirection { return SyncPull }
+
+// SyncOptions configure how a LibraryDiff is turned into a SyncPlan.
+type SyncOptions struct {
+	// Conflict resolves the changed entries. If nil, NewerWins is used.
+	Conflict ConflictPolicy
+
+	// RemoveRemote makes entries that are only in the remote library be
+	// removed from it. By default they are pulled into the local library
+	// instead.
+	RemoveRemote bool
+}
+
+// SyncPlan holds the changes that bring a local and a remote library in sync.
+type SyncPlan struct {
+	// Remote are the calls to make to the remote library.
+	Remote []SyncOp
+
+	// Pull are the remote entries that should replace or be added to the
+	// local library.
+	Pull []LibraryEntry
+
+	// Skipped are the local entries that can't be sent to the remote
+	// library, such as entries with an invalid rating. ApplySync reports
+	// them as an error.
+	Skipped []SkippedEntry
+}
+
+// SkippedEntry is a local entry that a SyncPlan doesn't send, with the
+// reason.
+type SkippedEntry struct {
+	AnimeID int
+	Err     error
+}
+
+// SyncOp is a call to LibraryService.Update or LibraryService.Remove.
+type SyncOp struct {
+	// AnimeID is the ID of the anime the call is about.
+	AnimeID int
+
+	// Remove is true for calls to LibraryService.Remove.
+	Remove bool
+
+	// Entry holds the values sent to LibraryService.Update.
+	Entry *Entry
+}
+
+// Plan turns the diff into the changes that bring both libraries in sync
+// according to opts. Local entries that are missing from the remote library
+// are always added to it.
+func (d *LibraryDiff) Plan(opts SyncOptions) *SyncPlan {
+	conflict := opts.Conflict
+	if conflict == nil {
+		conflict = NewerWins
+	}
+
+	p := new(SyncPlan)
+	push := func(animeID int, e LibraryEntry, fields []string) {
+		entry, err := entryFromLibraryEntry(e, fields)
+		if err != nil {
+			p.Skipped = append(p.Skipped, SkippedEntry{AnimeID: animeID, Err: err})
+			return
+		}
+		p.Remote = append(p.Remote, SyncOp{AnimeID: animeID, Entry: entry})
+	}
+	for _, e := range d.Added {
+		push(e.Anime.ID, e, nil)
+	}
+	for _, c := range d.Changed {
+		switch conflict(c) {
+		case SyncPush:
+			push(c.AnimeID, c.Local, c.Fields)
+		case SyncPull:
+			p.Pull = append(p.Pull, c.Remote)
+		}
+	}
+	for _, e := range d.Removed {
+		if opts.RemoveRemote {
+			p.Remote = append(p.Remote, SyncOp{AnimeID: e.Anime.ID, Remove: true})
+		} else {
+			p.Pull = append(p.Pull, e)
+		}
+	}
+	return p
+}
+
+// entryFromLibraryEntry returns the Entry that sets the given fields of a
+// library entry, or all of them if fields is nil. The status is always set
+// since the API doesn't add entries without one. It returns an error if the
+// rating is to be set but is not valid, rather than removing it.
+func entryFromLibraryEntry(e LibraryEntry, fields []string) (*Entry, error) {
+	all := fields == nil
+	has := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		has[f] = true
+	}
+
+	entry := &Entry{Status: e.Status}
+	if entry.Status == "" {
+		entry.Status = StatusCurrentlyWatching
+	}
+	if all || has["episodes_watched"] {
+		entry.EpisodesWatched = Int(e.EpisodesWatched)
+	}
+	if all && e.Rating != nil || has["rating"] {
+		// Only an entry without a rating removes the remote one.
+		entry.SaneRatingUpdate = NewRating(0)
+		if e.Rating != nil {
+			r, err := e.Rating.Rating()
+			if err != nil {
+				return nil, err
+			}
+			if err := r.validate(); err != nil {
+				return nil, err
+			}
+			entry.SaneRatingUpdate = &r
+		}
+	}
+	if all || has["rewatched_times"] {
+		entry.RewatchedTimes = Int(e.RewatchedTimes)
+	}
+	if all || has["rewatching"] {
+		entry.Rewatching = Bool(e.Rewatching)
+	}
+	if all || has["notes"] {
+		entry.Notes = String(e.Notes)
+	}
+	if all || has["private"] {
+		entry.Privacy = "public"
+		if e.Private {
+			entry.Privacy = "private"
+		}
+	}
+	return entry, nil
+}
+
+// ApplySync makes the calls in the Remote part of a sync plan to the library
+// of the user that authToken belongs to. It stops at the first call that
+// fails and returns the number of calls that succeeded. Since a sync plan
+// only brings the libraries closer together, a failed sync can be retried by
+// diffing the libraries again.
+//
+// If the plan has Skipped entries, the calls are still made and an error
+// about the skipped entries is returned after them.
+func (s *LibraryService) ApplySync(ctx context.Context, authToken string, p *SyncPlan) (int, error) {
+	for i, op := range p.Remote {
+		animeID := strconv.Itoa(op.AnimeID)
+		var err error
+		if op.Remove {
+			_, _, err = s.RemoveContext(ctx, animeID, authToken)
+		} else {
+			entry := *op.Entry
+			_, _, err = s.UpdateContext(ctx, animeID, authToken, &entry)
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+	if len(p.Skipped) > 0 {
+		sk := p.Skipped[0]
+		return len(p.Remote), fmt.Errorf("hb: skipped %d entries, anime %d: %w", len(p.Skipped), sk.AnimeID, sk.Err)
+	}
+	return len(p.Remote), nil
+}
@@ -0,0 +1,226 @@
+package hb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffLibrary(t *testing.T) {
+	local := []LibraryEntry{
+		{Status: StatusCompleted, EpisodesWatched: 25, Anime: &Anime{ID: 1}},
+		{Status: StatusCurrentlyWatching, EpisodesWatched: 3, Anime: &Anime{ID: 2},
+			Rating: &LibraryEntryRating{Type: "advanced", Value: "4"}},
+		{Status: StatusPlanToWatch, Anime: &Anime{ID: 3}},
+		{Status: StatusDropped},
+	}
+	remote := []LibraryEntry{
+		{Status: StatusCurrentlyWatching, EpisodesWatched: 20, Anime: &Anime{ID: 1}},
+		{Status: StatusCurrentlyWatching, EpisodesWatched: 3, Anime: &Anime{ID: 2},
+			Rating: &LibraryEntryRating{Type: "advanced", Value: "4.0"}},
+		{Status: StatusOnHold, Anime: &Anime{ID: 4}},
+	}
+
+	d := DiffLibrary(local, remote)
+
+	if got, want := d.Added, local[2:3]; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLibrary added %+v, want %+v", got, want)
+	}
+	if got, want := d.Removed, remote[2:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLibrary removed %+v, want %+v", got, want)
+	}
+	want := []EntryDiff{{AnimeID: 1, Local: local[0], Remote: remote[0], Fields: []string{"status", "episodes_watched"}}}
+	if got := d.Changed; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffLibrary changed %+v, want %+v", got, want)
+	}
+}
+
+func TestNewerWins(t *testing.T) {
+	older := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		local, remote *time.Time
+		want          SyncDirection
+	}{
+		{&newer, &older, SyncPush},
+		{&older, &newer, SyncPull},
+		{&older, &older, SyncSkip},
+		{&older, nil, SyncPush},
+		{nil, &older, SyncPull},
+		{nil, nil, SyncSkip},
+	}
+	for _, tt := range tests {
+		d := EntryDiff{Local: LibraryEntry{UpdatedAt: tt.local}, Remote: LibraryEntry{UpdatedAt: tt.remote}}
+		if got := NewerWins(d); got != tt.want {
+			t.Errorf("NewerWins(%v, %v) is %v, want %v", tt.local, tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestLibraryDiff_Plan(t *testing.T) {
+	older := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	d := &LibraryDiff{
+		Added:   []LibraryEntry{{Status: StatusPlanToWatch, Anime: &Anime{ID: 3}}},
+		Removed: []LibraryEntry{{Status: StatusOnHold, Anime: &Anime{ID: 4}}},
+		Changed: []EntryDiff{
+			{
+				AnimeID: 1,
+				Local:   LibraryEntry{Status: StatusCompleted, EpisodesWatched: 25, UpdatedAt: &newer},
+				Remote:  LibraryEntry{Status: StatusCurrentlyWatching, EpisodesWatched: 20, UpdatedAt: &older},
+				Fields:  []string{"status", "episodes_watched"},
+			},
+			{
+				AnimeID: 2,
+				Local:   LibraryEntry{Status: StatusCurrentlyWatching, Notes: "old", UpdatedAt: &older},
+				Remote:  LibraryEntry{Status: StatusCurrentlyWatching, Notes: "new", UpdatedAt: &newer},
+				Fields:  []string{"notes"},
+			},
+		},
+	}
+
+	p := d.Plan(SyncOptions{})
+	wantRemote := []SyncOp{
+		{AnimeID: 3, Entry: &Entry{Status: StatusPlanToWatch, Privacy: "public", Rewatching: Bool(false), RewatchedTimes: Int(0), Notes: String(""), EpisodesWatched: Int(0)}},
+		{AnimeID: 1, Entry: &Entry{Status: StatusCompleted, EpisodesWatched: Int(25)}},
+	}
+	if got := p.Remote; !reflect.DeepEqual(got, wantRemote) {
+		t.Errorf("Plan remote is %+v, want %+v", got, wantRemote)
+	}
+	wantPull := []LibraryEntry{d.Changed[1].Remote, d.Removed[0]}
+	if got := p.Pull; !reflect.DeepEqual(got, wantPull) {
+		t.Errorf("Plan pull is %+v, want %+v", got, wantPull)
+	}
+
+	p = d.Plan(SyncOptions{Conflict: RemoteWins, RemoveRemote: true})
+	wantRemote = []SyncOp{
+		{AnimeID: 3, Entry: &Entry{Status: StatusPlanToWatch, Privacy: "public", Rewatching: Bool(false), RewatchedTimes: Int(0), Notes: String(""), EpisodesWatched: Int(0)}},
+		{AnimeID: 4, Remove: true},
+	}
+	if got := p.Remote; !reflect.DeepEqual(got, wantRemote) {
+		t.Errorf("Plan remote with RemoteWins is %+v, want %+v", got, wantRemote)
+	}
+	if got, want := len(p.Pull), 2; got != want {
+		t.Errorf("Plan with RemoteWins pulls %v entries, want %v", got, want)
+	}
+}
+
+func TestLibraryService_ApplySync(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		if r.URL.Path == "/api/v1/libraries/4/remove" {
+			fmt.Fprintf(w, `true`)
+			return
+		}
+		fmt.Fprintf(w, `{"id":1}`)
+	})
+
+	p := &SyncPlan{Remote: []SyncOp{
+		{AnimeID: 3, Entry: &Entry{Status: StatusPlanToWatch}},
+		{AnimeID: 4, Remove: true},
+	}}
+	n, err := client.Library.ApplySync(context.Background(), "valid_user_token", p)
+	if err != nil {
+		t.Errorf("Library.ApplySync returned error %v", err)
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("Library.ApplySync applied %v calls, want %v", got, want)
+	}
+	want := []string{"/api/v1/libraries/3", "/api/v1/libraries/4/remove"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Library.ApplySync made calls %v, want %v", calls, want)
+	}
+	if p.Remote[0].Entry.AuthToken != "" {
+		t.Error("Library.ApplySync modified the entry of the plan.")
+	}
+}
+
+func TestLibraryService_ApplySync_zeroValues(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/1", func(w http.ResponseWriter, r *http.Request) {
+		requestBody := `{"id":"1","auth_token":"valid_user_token","status":"currently-watching","rewatching":false,"rewatched_times":0,"notes":"","episodes_watched":0}`
+		testBody(t, r, requestBody+"\n")
+		fmt.Fprintf(w, `{"id":1}`)
+	})
+
+	older := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	local := []LibraryEntry{{Status: StatusCurrentlyWatching, Anime: &Anime{ID: 1}, UpdatedAt: &newer}}
+	remote := []LibraryEntry{{Status: StatusCurrentlyWatching, Anime: &Anime{ID: 1}, UpdatedAt: &older,
+		EpisodesWatched: 10, RewatchedTimes: 2, Rewatching: true, Notes: "old"}}
+
+	p := DiffLibrary(local, remote).Plan(SyncOptions{})
+	if _, err := client.Library.ApplySync(context.Background(), "valid_user_token", p); err != nil {
+		t.Errorf("Library.ApplySync returned error %v", err)
+	}
+}
+
+func TestLibraryService_ApplySync_invalidRating(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Request sent for an entry with an invalid rating: %v", r.URL)
+	})
+
+	older := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	remoteRating := &LibraryEntryRating{Type: RatingTypeAdvanced, Value: "4.0"}
+	local := []LibraryEntry{
+		{Status: StatusCompleted, Anime: &Anime{ID: 1}, UpdatedAt: &newer, Rating: &LibraryEntryRating{Type: RatingTypeAdvanced, Value: "great"}},
+		{Status: StatusCompleted, Anime: &Anime{ID: 2}, UpdatedAt: &newer, Rating: &LibraryEntryRating{Type: RatingTypeAdvanced, Value: "4.3"}},
+	}
+	remote := []LibraryEntry{
+		{Status: StatusCompleted, Anime: &Anime{ID: 1}, UpdatedAt: &older, Rating: remoteRating},
+		{Status: StatusCompleted, Anime: &Anime{ID: 2}, UpdatedAt: &older, Rating: remoteRating},
+	}
+
+	// The ratings must not be removed from the remote library.
+	p := DiffLibrary(local, remote).Plan(SyncOptions{})
+	if len(p.Remote) != 0 {
+		t.Errorf("Plan Remote is %+v, want none", p.Remote)
+	}
+	if got, want := len(p.Skipped), 2; got != want {
+		t.Fatalf("Plan skipped %v entries, want %v", got, want)
+	}
+	if got, want := p.Skipped[0].AnimeID, 1; got != want {
+		t.Errorf("Plan skipped anime %v, want %v", got, want)
+	}
+
+	n, err := client.Library.ApplySync(context.Background(), "valid_user_token", p)
+	if err == nil {
+		t.Error("Library.ApplySync with skipped entries returned no error")
+	}
+	if n != 0 {
+		t.Errorf("Library.ApplySync applied %v calls, want 0", n)
+	}
+}
+
+func TestLibraryService_ApplySync_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Invalid authentication token"}`, http.StatusUnauthorized)
+	})
+
+	p := &SyncPlan{Remote: []SyncOp{{AnimeID: 3, Entry: &Entry{Status: StatusPlanToWatch}}, {AnimeID: 4, Remove: true}}}
+	n, err := client.Library.ApplySync(context.Background(), "invalid_user_token", p)
+	if err == nil {
+		t.Error("Expected HTTP 401 error.")
+	}
+	if got, want := n, 0; got != want {
+		t.Errorf("Library.ApplySync applied %v calls, want %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package hb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// redacted replaces the values of sensitive fields in logs.
+const redacted = "REDACTED"
+
+// sensitiveFields are the names of the JSON fields and query parameters whose
+// values are never logged.
+var sensitiveFields = map[string]bool{
+	"auth_token": true,
+	"password":   true,
+}
+
+// sensitiveResponses are the names of the service methods whose successful
+// responses are never logged, such as User.Authenticate whose response is the
+// bare token.
+var sensitiveResponses = map[string]bool{
+	"User.Authenticate": true,
+}
+
+// LogValue implements slog.LogValuer so that the authentication token of an
+// Entry is redacted when it is logged.
+func (e Entry) LogValue() slog.Value {
+	// entry has the fields of Entry but not its LogValue method.
+	type entry Entry
+	if e.AuthToken != "" {
+		e.AuthToken = redacted
+	}
+	return slog.AnyValue(entry(e))
+}
+
+// LogValue implements slog.LogValuer so that the password is redacted when
+// the authentication payload is logged.
+func (a auth) LogValue() slog.Value {
+	type authPayload auth
+	if a.Password != "" {
+		a.Password = redacted
+	}
+	return slog.AnyValue(authPayload(a))
+}
+
+// logCall logs a completed API call. Calls that failed are logged at error
+// level and the rest at info level.
+func (c *Client) logCall(ctx context.Context, call *Call, resp *http.Response, err error, d time.Duration) {
+	attrs := []slog.Attr{
+		slog.String("method", call.Request.Method),
+		slog.String("url", redactURL(call.Request.URL)),
+		slog.Duration("duration", d),
+	}
+	if call.Method != "" {
+		attrs = append(attrs, slog.String("call", call.Method))
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if resp.Header.Get("X-From-Cache") != "" {
+			attrs = append(attrs, slog.Bool("cached", true))
+		}
+	}
+	level, msg := slog.LevelInfo, "hb: api call"
+	if err != nil {
+		level, msg = slog.LevelError, "hb: api call failed"
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logRetry logs that a request is going to be sent again after wait.
+func (c *Client) logRetry(ctx context.Context, req *http.Request, attempt int, resp *http.Response, err error, wait time.Duration) {
+	if c.Logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Int("attempt", attempt),
+		slog.Duration("wait", wait),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.Logger.LogAttrs(ctx, slog.LevelWarn, "hb: retrying request", attrs...)
+}
+
+// dumpRequest logs the body of req at debug level if the Client is in Debug
+// mode.
+func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
+	if c.Logger == nil || !c.Debug || req.GetBody == nil {
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return
+	}
+	c.Logger.LogAttrs(ctx, slog.LevelDebug, "hb: request body",
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.String("body", string(redactJSON(b))),
+	)
+}
+
+// dumpResponse logs the body of resp at debug level if the Client is in Debug
+// mode. The body of resp is replaced so that it can still be read.
+func (c *Client) dumpResponse(ctx context.Context, resp *http.Response) {
+	if c.Logger == nil || !c.Debug {
+		return
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return
+	}
+	body := string(redactJSON(b))
+	if sensitiveResponses[methodName(ctx)] && resp.StatusCode < 300 {
+		body = redacted
+	}
+	c.Logger.LogAttrs(ctx, slog.LevelDebug, "hb: response body",
+		slog.Int("status", resp.StatusCode),
+		slog.String("body", body),
+	)
+}
+
+// redactJSON returns body with the values of sensitive fields replaced. Bodies
+// that are not JSON are returned as is.
+func redactJSON(body []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range v {
+			if sensitiveFields[k] {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(vv)
+			}
+		}
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = redactValue(vv)
+		}
+	}
+	return v
+}
+
+// redactURL returns u as a string with the values of sensitive query
+// parameters replaced.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	changed := false
+	for k := range q {
+		if sensitiveFields[k] {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	uu := *u
+	uu.RawQuery = q.Encode()
+	return uu.String()
+}
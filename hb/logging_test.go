@@ -0,0 +1,103 @@
+package hb
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_Logger(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	client.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"title":"Log Horizon"}`)
+	})
+
+	client.Anime.Get("log-horizon", "")
+
+	out := buf.String()
+	for _, want := range []string{"level=INFO", `msg="hb: api call"`, "call=Anime.Get", "status=200"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Log output %q does not contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "Log Horizon") {
+		t.Errorf("Log output %q contains response body without Debug", out)
+	}
+}
+
+func TestClient_Logger_debugRedacted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	client.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client.Debug = true
+
+	mux.HandleFunc("/api/v1/users/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `"token1234"`)
+	})
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":7622,"notes":"crazy"}`)
+	})
+
+	client.User.Authenticate("TestUser", "", "secret_password")
+	client.Library.Update("nichijou", "secret_token", &Entry{Notes: String("crazy")})
+
+	out := buf.String()
+	for _, secret := range []string{"secret_password", "secret_token", "token1234"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Log output %q contains %q", out, secret)
+		}
+	}
+	for _, want := range []string{"level=DEBUG", "REDACTED", "TestUser", "crazy"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Log output %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestClient_Logger_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	client.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+	mux.HandleFunc("/api/v1/users/TestUser", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	client.User.Get("TestUser")
+
+	if out, want := buf.String(), "level=ERROR"; !strings.Contains(out, want) {
+		t.Errorf("Log output %q does not contain %q", out, want)
+	}
+}
+
+func TestEntry_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger.Info("update", "entry", Entry{ID: "nichijou", AuthToken: "secret_token", Status: StatusCompleted})
+	logger.Info("login", "auth", auth{Username: "TestUser", Password: "secret_password"})
+
+	out := buf.String()
+	for _, secret := range []string{"secret_password", "secret_token", "token1234"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Log output %q contains %q", out, secret)
+		}
+	}
+	for _, want := range []string{`"auth_token":"REDACTED"`, `"password":"REDACTED"`, `"status":"completed"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Log output %q does not contain %q", out, want)
+		}
+	}
+}
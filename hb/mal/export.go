@@ -0,0 +1,95 @@
+package mal
+
+import (
+	"math"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Export converts the library entries of a Hummingbird user to a MyAnimeList
+// export. Entries whose anime has no MyAnimeList ID cannot be exported and
+// are returned as skipped.
+func Export(username string, entries []hb.LibraryEntry) (l *Library, skipped []hb.LibraryEntry) {
+	l = &Library{Info: Info{ExportType: 1, UserName: username}}
+	for _, e := range entries {
+		if e.Anime == nil || e.Anime.MALID == 0 {
+			skipped = append(skipped, e)
+			continue
+		}
+		a := exportEntry(e)
+		l.Anime = append(l.Anime, a)
+
+		l.Info.TotalAnime++
+		switch a.Status {
+		case StatusWatching:
+			l.Info.TotalWatching++
+		case StatusCompleted:
+			l.Info.TotalCompleted++
+		case StatusOnHold:
+			l.Info.TotalOnHold++
+		case StatusDropped:
+			l.Info.TotalDropped++
+		case StatusPlanToWatch:
+			l.Info.TotalPlanToWatch++
+		}
+	}
+	return l, skipped
+}
+
+func exportEntry(e hb.LibraryEntry) Anime {
+	a := Anime{
+		ID:              e.Anime.MALID,
+		Title:           CDATA{e.Anime.Title},
+		Type:            e.Anime.ShowType,
+		Episodes:        e.Anime.EpisodeCount,
+		WatchedEpisodes: e.EpisodesWatched,
+		StartDate:       noDate,
+		FinishDate:      noDate,
+		Score:           Score(e.Rating),
+		Status:          Status(e.Status),
+		Comments:        CDATA{e.Notes},
+		TimesWatched:    e.RewatchedTimes,
+		UpdateOnImport:  1,
+	}
+	if e.Status == hb.StatusCompleted && e.LastWatched != nil && !e.LastWatched.IsZero() {
+		a.FinishDate = e.LastWatched.Format("2006-01-02")
+	}
+	if e.Rewatching {
+		a.Rewatching = 1
+		a.RewatchingEp = e.EpisodesWatched
+	}
+	return a
+}
+
+// Status returns the MyAnimeList status that corresponds to a Hummingbird
+// library status or the empty string if there is none.
+func Status(status hb.LibraryStatus) string {
+	switch status {
+	case hb.StatusCurrentlyWatching:
+		return StatusWatching
+	case hb.StatusCompleted:
+		return StatusCompleted
+	case hb.StatusOnHold:
+		return StatusOnHold
+	case hb.StatusDropped:
+		return StatusDropped
+	case hb.StatusPlanToWatch:
+		return StatusPlanToWatch
+	}
+	return ""
+}
+
+// Score converts the rating of a library entry to a MyAnimeList score from 1
+// to 10. Ratings are converted to advanced, from 0 to 5, and doubled, so
+// simple ratings become 2, 6 and 9. Zero is returned for entries that are
+// not rated.
+func Score(r *hb.LibraryEntryRating) int {
+	if r == nil {
+		return 0
+	}
+	v, err := r.Rating()
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return int(math.Min(10, math.Round(float64(v)*2)))
+}
@@ -0,0 +1,115 @@
+package mal
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func TestExport(t *testing.T) {
+	watched := time.Date(2014, 3, 22, 10, 0, 0, 0, time.UTC)
+	entries := []hb.LibraryEntry{
+		{
+			EpisodesWatched: 25,
+			LastWatched:     &hb.Date{Time: watched},
+			Status:          hb.StatusCompleted,
+			RewatchedTimes:  1,
+			Notes:           "Great <3",
+			Anime:           &hb.Anime{MALID: 17265, Title: "Log Horizon", ShowType: "TV", EpisodeCount: 25},
+			Rating:          &hb.LibraryEntryRating{Type: "advanced", Value: "4.5"},
+		},
+		{
+			EpisodesWatched: 3,
+			Status:          hb.StatusCurrentlyWatching,
+			Anime:           &hb.Anime{MALID: 10165, Title: "Nichijou"},
+			Rating:          &hb.LibraryEntryRating{Type: "simple", Value: "positive"},
+		},
+		{
+			Status: hb.StatusPlanToWatch,
+			Anime:  &hb.Anime{Title: "Not on MAL"},
+		},
+	}
+
+	l, skipped := Export("cybrox", entries)
+
+	if got, want := skipped, entries[2:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Export skipped %+v, want %+v", got, want)
+	}
+	wantInfo := Info{ExportType: 1, UserName: "cybrox", TotalAnime: 2, TotalWatching: 1, TotalCompleted: 1}
+	if got := l.Info; got != wantInfo {
+		t.Errorf("Export info is %+v, want %+v", got, wantInfo)
+	}
+	wantAnime := []Anime{
+		{
+			ID: 17265, Title: CDATA{"Log Horizon"}, Type: "TV", Episodes: 25,
+			WatchedEpisodes: 25, StartDate: noDate, FinishDate: "2014-03-22",
+			Score: 9, Status: StatusCompleted, Comments: CDATA{"Great <3"},
+			TimesWatched: 1, UpdateOnImport: 1,
+		},
+		{
+			ID: 10165, Title: CDATA{"Nichijou"}, WatchedEpisodes: 3,
+			StartDate: noDate, FinishDate: noDate, Score: 9,
+			Status: StatusWatching, UpdateOnImport: 1,
+		},
+	}
+	if got := l.Anime; !reflect.DeepEqual(got, wantAnime) {
+		t.Errorf("Export anime are %+v, want %+v", got, wantAnime)
+	}
+}
+
+func TestLibrary_Encode(t *testing.T) {
+	l, _ := Export("cybrox", []hb.LibraryEntry{{
+		Status: hb.StatusOnHold,
+		Anime:  &hb.Anime{MALID: 1, Title: "Cowboy Bebop"},
+	}})
+
+	var buf bytes.Buffer
+	if err := l.Encode(&buf); err != nil {
+		t.Fatalf("Encode returned error %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		"<myanimelist>",
+		"<user_total_onhold>1</user_total_onhold>",
+		"<series_title><![CDATA[Cowboy Bebop]]></series_title>",
+		"<my_status>On-Hold</my_status>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Encode output %q does not contain %q", out, want)
+		}
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode returned error %v", err)
+	}
+	if got, want := decoded.Anime, l.Anime; !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode anime are %+v, want %+v", got, want)
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		rating *hb.LibraryEntryRating
+		want   int
+	}{
+		{nil, 0},
+		{&hb.LibraryEntryRating{Type: "advanced", Value: "0"}, 0},
+		{&hb.LibraryEntryRating{Type: "advanced", Value: "0.5"}, 1},
+		{&hb.LibraryEntryRating{Type: "advanced", Value: "3.5"}, 7},
+		{&hb.LibraryEntryRating{Type: "advanced", Value: "5.0"}, 10},
+		{&hb.LibraryEntryRating{Type: "simple", Value: "negative"}, 2},
+		{&hb.LibraryEntryRating{Type: "simple", Value: "neutral"}, 6},
+		{&hb.LibraryEntryRating{Type: "simple", Value: "positive"}, 9},
+	}
+	for _, tt := range tests {
+		if got := Score(tt.rating); got != tt.want {
+			t.Errorf("Score(%+v) is %v, want %v", tt.rating, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package mal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Importer turns a MyAnimeList export into library updates of a Hummingbird
+// user. Importing is done in two steps: Plan compares the export with the
+// current library of the user without changing anything, which can be used
+// as a dry run, and Apply sends the planned updates.
+type Importer struct {
+	Client *hb.Client
+
+	// LookupMALID, if set, returns the Hummingbird anime that has the given
+	// MyAnimeList ID or nil if there is none. It is used for anime that are
+	// not in the library of the user, before falling back to searching by
+	// title.
+	LookupMALID func(ctx context.Context, malID int) (*hb.Anime, error)
+}
+
+// Plan is the result of comparing a MyAnimeList export with the library of a
+// Hummingbird user.
+type Plan struct {
+	// Changes are the entries that will be added or updated.
+	Changes []Change
+
+	// Unchanged is the number of entries that are already up to date.
+	Unchanged int
+
+	// Unmatched are the entries of the export that no Hummingbird anime was
+	// found for.
+	Unmatched []Anime
+}
+
+// Change is a library entry that will be added or updated.
+type Change struct {
+	// MAL is the entry of the export.
+	MAL Anime
+
+	// Anime is the Hummingbird anime the entry was matched with.
+	Anime *hb.Anime
+
+	// Current is the library entry of the user or nil if the anime will be
+	// added to the library.
+	Current *hb.LibraryEntry
+
+	// Entry holds the values that will be sent to LibraryService.Update.
+	Entry hb.Entry
+
+	// Fields are the names of the fields that differ from the current entry.
+	Fields []string
+}
+
+// IsAdd reports whether the change adds the anime to the library.
+func (c Change) IsAdd() bool {
+	return c.Current == nil
+}
+
+// Plan compares the MyAnimeList export l with the library of username and
+// returns the changes needed to bring the library up to date. Nothing is
+// changed in the library.
+func (im *Importer) Plan(ctx context.Context, username string, l *Library) (*Plan, error) {
+	entries, _, err := im.Client.User.LibraryContext(ctx, username, "")
+	if err != nil {
+		return nil, err
+	}
+	byMALID := make(map[int]*hb.LibraryEntry)
+	for i := range entries {
+		if a := entries[i].Anime; a != nil && a.MALID != 0 {
+			byMALID[a.MALID] = &entries[i]
+		}
+	}
+
+	p := new(Plan)
+	for _, m := range l.Anime {
+		current := byMALID[m.ID]
+		var anime *hb.Anime
+		if current != nil {
+			anime = current.Anime
+		} else {
+			anime, err = im.resolve(ctx, m)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if anime == nil {
+			p.Unmatched = append(p.Unmatched, m)
+			continue
+		}
+
+		c := newChange(m, anime, current)
+		if len(c.Fields) == 0 {
+			p.Unchanged++
+			continue
+		}
+		p.Changes = append(p.Changes, c)
+	}
+	return p, nil
+}
+
+// resolve finds the Hummingbird anime of a MyAnimeList entry that is not in
+// the library of the user.
+func (im *Importer) resolve(ctx context.Context, m Anime) (*hb.Anime, error) {
+	if im.LookupMALID != nil {
+		a, err := im.LookupMALID(ctx, m.ID)
+		if err != nil || a != nil {
+			return a, err
+		}
+	}
+	if m.Title.Text == "" {
+		return nil, nil
+	}
+	results, _, err := im.Client.Anime.SearchContext(ctx, m.Title.Text)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if results[i].MALID == m.ID {
+			return &results[i], nil
+		}
+	}
+	for i := range results {
+		if strings.EqualFold(results[i].Title, m.Title.Text) ||
+			strings.EqualFold(results[i].AlternateTitle, m.Title.Text) {
+			return &results[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// newChange returns the change that brings the library entry current, which
+// may be nil, in line with the MyAnimeList entry m.
+func newChange(m Anime, anime *hb.Anime, current *hb.LibraryEntry) Change {
+	c := Change{MAL: m, Anime: anime, Current: current}
+	c.Entry.Status = libraryStatus(m.Status)
+	if c.Entry.Status == "" {
+		c.Entry.Status = hb.StatusPlanToWatch
+	}
+
+	var cur hb.LibraryEntry
+	if current != nil {
+		cur = *current
+	}
+	if cur.Status != c.Entry.Status {
+		c.Fields = append(c.Fields, "status")
+	}
+	if m.WatchedEpisodes != cur.EpisodesWatched {
+		c.Entry.EpisodesWatched = hb.Int(m.WatchedEpisodes)
+		c.Fields = append(c.Fields, "episodes_watched")
+	}
+	if r := Rating(m.Score); r > 0 && Score(cur.Rating) != m.Score {
+		c.Entry.SaneRatingUpdate = &r
+		c.Fields = append(c.Fields, "rating")
+	}
+	if m.TimesWatched != cur.RewatchedTimes {
+		c.Entry.RewatchedTimes = hb.Int(m.TimesWatched)
+		c.Fields = append(c.Fields, "rewatched_times")
+	}
+	if rewatching := m.Rewatching == 1; rewatching != cur.Rewatching {
+		c.Entry.Rewatching = hb.Bool(rewatching)
+		c.Fields = append(c.Fields, "rewatching")
+	}
+	if m.Comments.Text != cur.Notes {
+		c.Entry.Notes = hb.String(m.Comments.Text)
+		c.Fields = append(c.Fields, "notes")
+	}
+	return c
+}
+
+// libraryStatus returns the Hummingbird library status that corresponds to a
+// MyAnimeList status or the empty string if there is none. MyAnimeList
+// exports also use numbers for statuses.
+func libraryStatus(status string) hb.LibraryStatus {
+	switch status {
+	case StatusWatching, "1":
+		return hb.StatusCurrentlyWatching
+	case StatusCompleted, "2":
+		return hb.StatusCompleted
+	case StatusOnHold, "3":
+		return hb.StatusOnHold
+	case StatusDropped, "4":
+		return hb.StatusDropped
+	case StatusPlanToWatch, "6":
+		return hb.StatusPlanToWatch
+	}
+	return ""
+}
+
+// Rating converts a MyAnimeList score from 1 to 10 to an advanced Hummingbird
+// rating from 0.5 to 5 as used by Entry.SaneRatingUpdate. Zero is returned
+// for a score of zero, which means the entry is not rated.
+func Rating(score int) hb.Rating {
+	if score <= 0 {
+		return 0
+	}
+	return hb.Rating(math.Min(5, float64(score)/2))
+}
+
+// Report writes a human readable summary of the plan to w.
+func (p *Plan) Report(w io.Writer) error {
+	var buf bytes.Buffer
+	adds := 0
+	for _, c := range p.Changes {
+		if c.IsAdd() {
+			adds++
+		}
+	}
+	fmt.Fprintf(&buf, "%d to add, %d to update, %d unchanged, %d unmatched\n",
+		adds, len(p.Changes)-adds, p.Unchanged, len(p.Unmatched))
+	for _, c := range p.Changes {
+		action := "update"
+		if c.IsAdd() {
+			action = "add"
+		}
+		fmt.Fprintf(&buf, "%s\t%s\t%s\n", action, c.Anime.Title, strings.Join(c.Fields, ", "))
+	}
+	for _, m := range p.Unmatched {
+		fmt.Fprintf(&buf, "unmatched\t%s\t(MAL ID %d)\n", m.Title.Text, m.ID)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Progress reports the progress of Apply after each change is sent.
+type Progress struct {
+	// Done is the number of changes sent so far, including this one, out of
+	// Total.
+	Done, Total int
+
+	// Change is the change that was sent and Err the error it failed with,
+	// if any.
+	Change Change
+	Err    error
+}
+
+// ApplyReport is the result of applying a plan.
+type ApplyReport struct {
+	// Applied are the changes that were applied successfully.
+	Applied []Change
+
+	// Failed are the changes that could not be applied along with their
+	// errors.
+	Failed []ChangeError
+}
+
+// ChangeError is a change that failed to be applied.
+type ChangeError struct {
+	Change Change
+	Err    error
+}
+
+func (e ChangeError) Error() string {
+	return fmt.Sprintf("mal: %s: %v", e.Change.MAL.Title.Text, e.Err)
+}
+
+// Apply sends the changes of p to the library of the user that authToken
+// belongs to. A change that fails does not stop the rest from being sent.
+// Progress, if not nil, is called after each change. If ctx is done, Apply
+// stops and returns its error along with the report of the changes sent so
+// far.
+func (im *Importer) Apply(ctx context.Context, p *Plan, authToken string, progress func(Progress)) (*ApplyReport, error) {
+	report := new(ApplyReport)
+	for i, c := range p.Changes {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		entry := c.Entry
+		_, _, err := im.Client.Library.UpdateContext(ctx, strconv.Itoa(c.Anime.ID), authToken, &entry)
+		if err != nil {
+			report.Failed = append(report.Failed, ChangeError{Change: c, Err: err})
+		} else {
+			report.Applied = append(report.Applied, c)
+		}
+		if progress != nil {
+			progress(Progress{Done: i + 1, Total: len(p.Changes), Change: c, Err: err})
+		}
+	}
+	return report, nil
+}
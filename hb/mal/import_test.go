@@ -0,0 +1,207 @@
+package mal
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nstratos/go-hummingbird/hb"
+	"github.com/nstratos/go-hummingbird/hb/hbtest"
+)
+
+const testExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<myinfo>
+		<user_export_type>1</user_export_type>
+		<user_name>cybrox</user_name>
+	</myinfo>
+	<anime>
+		<series_animedb_id>17265</series_animedb_id>
+		<series_title><![CDATA[Log Horizon]]></series_title>
+		<my_watched_episodes>25</my_watched_episodes>
+		<my_score>9</my_score>
+		<my_status>Completed</my_status>
+		<my_comments><![CDATA[]]></my_comments>
+	</anime>
+	<anime>
+		<series_animedb_id>10165</series_animedb_id>
+		<series_title><![CDATA[Nichijou]]></series_title>
+		<my_watched_episodes>26</my_watched_episodes>
+		<my_score>10</my_score>
+		<my_status>Completed</my_status>
+		<my_comments><![CDATA[So random]]></my_comments>
+	</anime>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<my_watched_episodes>10</my_watched_episodes>
+		<my_status>On-Hold</my_status>
+	</anime>
+	<anime>
+		<series_animedb_id>5081</series_animedb_id>
+		<series_title><![CDATA[Bakemonogatari]]></series_title>
+		<my_status>Plan to Watch</my_status>
+	</anime>
+</myanimelist>
+`
+
+func newTestImport(t *testing.T) (*hbtest.Server, *Importer, *Library) {
+	srv := hbtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	f := hbtest.DefaultFixtures()
+	// Cowboy Bebop is not in the library so that it has to be searched.
+	f.Users[0].Library = f.Users[0].Library[:3]
+	srv.Seed(f)
+
+	l, err := Decode(strings.NewReader(testExport))
+	if err != nil {
+		t.Fatalf("Decode returned error %v", err)
+	}
+	return srv, &Importer{Client: srv.Client()}, l
+}
+
+func TestImporter_Plan(t *testing.T) {
+	_, im, l := newTestImport(t)
+
+	p, err := im.Plan(context.Background(), "cybrox", l)
+	if err != nil {
+		t.Fatalf("Plan returned error %v", err)
+	}
+
+	// Log Horizon is already completed with a 4.5 rating.
+	if got, want := p.Unchanged, 1; got != want {
+		t.Errorf("Plan unchanged is %v, want %v", got, want)
+	}
+	if got, want := len(p.Changes), 2; got != want {
+		t.Fatalf("Plan has %v changes, want %v", got, want)
+	}
+
+	nichijou := p.Changes[0]
+	if nichijou.IsAdd() {
+		t.Error("Nichijou change is an add, want an update.")
+	}
+	wantFields := []string{"status", "episodes_watched", "rating", "notes"}
+	if got := nichijou.Fields; !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("Nichijou change fields are %v, want %v", got, wantFields)
+	}
+	wantEntry := hb.Entry{Status: hb.StatusCompleted, EpisodesWatched: hb.Int(26), SaneRatingUpdate: hb.NewRating(5), Notes: hb.String("So random")}
+	if got := nichijou.Entry; !reflect.DeepEqual(got, wantEntry) {
+		t.Errorf("Nichijou entry is %+v, want %+v", got, wantEntry)
+	}
+
+	bebop := p.Changes[1]
+	if !bebop.IsAdd() || bebop.Anime.ID != 1 {
+		t.Errorf("Cowboy Bebop change is %+v, want an add of anime 1", bebop)
+	}
+
+	if len(p.Unmatched) != 1 || p.Unmatched[0].ID != 5081 {
+		t.Errorf("Plan unmatched are %+v, want Bakemonogatari", p.Unmatched)
+	}
+
+	var buf bytes.Buffer
+	p.Report(&buf)
+	if want := "1 to add, 1 to update, 1 unchanged, 1 unmatched\n"; !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("Report is %q, want it to start with %q", buf.String(), want)
+	}
+}
+
+func TestImporter_Apply(t *testing.T) {
+	srv, im, l := newTestImport(t)
+
+	p, err := im.Plan(context.Background(), "cybrox", l)
+	if err != nil {
+		t.Fatalf("Plan returned error %v", err)
+	}
+
+	var progress []int
+	report, err := im.Apply(context.Background(), p, srv.Token("cybrox"), func(pr Progress) {
+		if pr.Err != nil {
+			t.Errorf("Progress reported error %v", pr.Err)
+		}
+		progress = append(progress, pr.Done)
+	})
+	if err != nil {
+		t.Fatalf("Apply returned error %v", err)
+	}
+	if got, want := len(report.Applied), 2; got != want {
+		t.Errorf("Apply applied %v changes, want %v", got, want)
+	}
+	if got, want := progress, []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply progress is %v, want %v", got, want)
+	}
+
+	// Planning again finds nothing left to change.
+	p, err = im.Plan(context.Background(), "cybrox", l)
+	if err != nil {
+		t.Fatalf("Plan returned error %v", err)
+	}
+	if got, want := len(p.Changes), 0; got != want {
+		t.Errorf("Plan after Apply has %v changes, want %v: %+v", got, want, p.Changes)
+	}
+}
+
+func TestImporter_Apply_zeroValues(t *testing.T) {
+	srv := hbtest.NewServer()
+	t.Cleanup(srv.Close)
+	f := hbtest.DefaultFixtures()
+	nichijou := &f.Users[0].Library[1]
+	nichijou.Notes, nichijou.Rewatching, nichijou.RewatchedTimes = "old", true, 2
+	srv.Seed(f)
+
+	const export = `<myanimelist><anime>
+		<series_animedb_id>10165</series_animedb_id>
+		<series_title><![CDATA[Nichijou]]></series_title>
+		<my_watched_episodes>0</my_watched_episodes>
+		<my_status>Watching</my_status>
+	</anime></myanimelist>`
+	l, err := Decode(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("Decode returned error %v", err)
+	}
+	im := &Importer{Client: srv.Client()}
+	p, err := im.Plan(context.Background(), "cybrox", l)
+	if err != nil {
+		t.Fatalf("Plan returned error %v", err)
+	}
+	wantFields := []string{"episodes_watched", "rewatched_times", "rewatching", "notes"}
+	if len(p.Changes) != 1 || !reflect.DeepEqual(p.Changes[0].Fields, wantFields) {
+		t.Fatalf("Plan changes are %+v, want fields %v", p.Changes, wantFields)
+	}
+
+	if _, err := im.Apply(context.Background(), p, srv.Token("cybrox"), nil); err != nil {
+		t.Fatalf("Apply returned error %v", err)
+	}
+
+	for _, e := range srv.Library("cybrox") {
+		if e.Anime.ID != nichijou.Anime.ID {
+			continue
+		}
+		if e.EpisodesWatched != 0 || e.RewatchedTimes != 0 || e.Rewatching || e.Notes != "" {
+			t.Errorf("Nichijou after Apply is %+v, want zero episodes, rewatches and notes", e)
+		}
+	}
+}
+
+func TestImporter_Apply_failed(t *testing.T) {
+	_, im, l := newTestImport(t)
+
+	p, _ := im.Plan(context.Background(), "cybrox", l)
+	report, err := im.Apply(context.Background(), p, "invalid_token", nil)
+	if err != nil {
+		t.Fatalf("Apply returned error %v", err)
+	}
+	if got, want := len(report.Failed), len(p.Changes); got != want {
+		t.Errorf("Apply failed %v changes, want %v", got, want)
+	}
+}
+
+func TestRating(t *testing.T) {
+	for score, want := range map[int]hb.Rating{0: 0, 1: 0.5, 7: 3.5, 10: 5} {
+		if got := Rating(score); got != want {
+			t.Errorf("Rating(%d) is %v, want %v", score, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package mal converts Hummingbird libraries to and from the XML export
+// format of MyAnimeList.
+//
+// MyAnimeList identifies anime by their MyAnimeList ID which is available in
+// the MALID field of hb.Anime.
+package mal
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// MyAnimeList statuses of an anime list entry.
+const (
+	StatusWatching    = "Watching"
+	StatusCompleted   = "Completed"
+	StatusOnHold      = "On-Hold"
+	StatusDropped     = "Dropped"
+	StatusPlanToWatch = "Plan to Watch"
+)
+
+// noDate is the value MyAnimeList uses for dates that are not set.
+const noDate = "0000-00-00"
+
+// Library is a MyAnimeList anime list export.
+type Library struct {
+	XMLName xml.Name `xml:"myanimelist"`
+	Info    Info     `xml:"myinfo"`
+	Anime   []Anime  `xml:"anime"`
+}
+
+// Info holds the details of the user of a MyAnimeList export.
+type Info struct {
+	ExportType       int    `xml:"user_export_type"`
+	UserName         string `xml:"user_name"`
+	TotalAnime       int    `xml:"user_total_anime"`
+	TotalWatching    int    `xml:"user_total_watching"`
+	TotalCompleted   int    `xml:"user_total_completed"`
+	TotalOnHold      int    `xml:"user_total_onhold"`
+	TotalDropped     int    `xml:"user_total_dropped"`
+	TotalPlanToWatch int    `xml:"user_total_plantowatch"`
+}
+
+// Anime is an entry of a MyAnimeList anime list export.
+type Anime struct {
+	ID              int    `xml:"series_animedb_id"`
+	Title           CDATA  `xml:"series_title"`
+	Type            string `xml:"series_type"`
+	Episodes        int    `xml:"series_episodes"`
+	MyID            int    `xml:"my_id"`
+	WatchedEpisodes int    `xml:"my_watched_episodes"`
+	StartDate       string `xml:"my_start_date"`
+	FinishDate      string `xml:"my_finish_date"`
+	Rated           string `xml:"my_rated"`
+	Score           int    `xml:"my_score"`
+	DVD             string `xml:"my_dvd"`
+	Storage         string `xml:"my_storage"`
+	Status          string `xml:"my_status"`
+	Comments        CDATA  `xml:"my_comments"`
+	TimesWatched    int    `xml:"my_times_watched"`
+	RewatchValue    string `xml:"my_rewatch_value"`
+	Tags            CDATA  `xml:"my_tags"`
+	Rewatching      int    `xml:"my_rewatching"`
+	RewatchingEp    int    `xml:"my_rewatching_ep"`
+	UpdateOnImport  int    `xml:"update_on_import"`
+}
+
+// CDATA is text that is written as a CDATA section like MyAnimeList does for
+// titles, comments and tags.
+type CDATA struct {
+	Text string `xml:",cdata"`
+}
+
+// Encode writes l as a MyAnimeList XML export to w.
+func (l *Library) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(l); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Decode reads a MyAnimeList XML export from r.
+func Decode(r io.Reader) (*Library, error) {
+	l := new(Library)
+	if err := xml.NewDecoder(r).Decode(l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
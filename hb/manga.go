@@ -0,0 +1,174 @@
+package hb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Media types of favorites and stories.
+const (
+	MediaTypeAnime = "Anime"
+	MediaTypeManga = "Manga"
+)
+
+// Manga represents a Hummingbird manga object. Version 1 of the API only
+// returns manga as the media of stories and favorites. Manga and their
+// library entries can be requested with package v2.
+type Manga struct {
+	ID                 int     `json:"id,omitempty"`
+	Slug               string  `json:"slug,omitempty"`
+	Status             string  `json:"status,omitempty"`
+	URL                string  `json:"url,omitempty"`
+	Title              string  `json:"title,omitempty"`
+	AlternateTitle     string  `json:"alternate_title,omitempty"`
+	ChapterCount       int     `json:"chapter_count,omitempty"`
+	VolumeCount        int     `json:"volume_count,omitempty"`
+	CoverImage         string  `json:"cover_image,omitempty"`
+	Synopsis           string  `json:"synopsis,omitempty"`
+	MangaType          string  `json:"manga_type,omitempty"`
+	StartedPublishing  *Date   `json:"started_publishing,omitempty"`
+	FinishedPublishing *Date   `json:"finished_publishing,omitempty"`
+	CommunityRating    float64 `json:"community_rating,omitempty"`
+	Genres             []Genre `json:"genres,omitempty"`
+	FavID              int     `json:"fav_id,omitempty"`   // When requesting user favorite manga.
+	FavRank            int     `json:"fav_rank,omitempty"` // When requesting user favorite manga.
+}
+
+// MangaLibraryStatus is the status of a manga library entry, such as the new
+// status of the status updates of manga stories, see
+// Substory.AsMangaStatusUpdate.
+type MangaLibraryStatus string
+
+// Manga library entry statuses.
+const (
+	StatusCurrentlyReading MangaLibraryStatus = "currently-reading"
+	StatusPlanToRead       MangaLibraryStatus = "plan-to-read"
+	StatusMangaCompleted   MangaLibraryStatus = "completed"
+	StatusMangaOnHold      MangaLibraryStatus = "on-hold"
+	StatusMangaDropped     MangaLibraryStatus = "dropped"
+)
+
+// mangaLibraryStatusNames maps the human friendly names of the manga
+// statuses, in lower case and with spaces instead of dashes and underscores,
+// to the statuses.
+var mangaLibraryStatusNames = map[string]MangaLibraryStatus{
+	"currently reading": StatusCurrentlyReading,
+	"reading":           StatusCurrentlyReading,
+	"current":           StatusCurrentlyReading,
+	"plan to read":      StatusPlanToRead,
+	"planned":           StatusPlanToRead,
+	"ptr":               StatusPlanToRead,
+	"completed":         StatusMangaCompleted,
+	"complete":          StatusMangaCompleted,
+	"on hold":           StatusMangaOnHold,
+	"onhold":            StatusMangaOnHold,
+	"dropped":           StatusMangaDropped,
+}
+
+// ParseMangaLibraryStatus parses a manga library status from either its API
+// form, such as "plan-to-read", or a human friendly form such as "Plan to
+// Read", "reading" or "on hold". It is case insensitive.
+func ParseMangaLibraryStatus(s string) (MangaLibraryStatus, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	if status, ok := mangaLibraryStatusNames[name]; ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("hb: invalid manga library status %q", s)
+}
+
+// Valid reports whether s is one of the manga library statuses of the API.
+func (s MangaLibraryStatus) Valid() bool {
+	switch s {
+	case StatusCurrentlyReading, StatusPlanToRead, StatusMangaCompleted, StatusMangaOnHold, StatusMangaDropped:
+		return true
+	}
+	return false
+}
+
+// String returns the API form of the status.
+func (s MangaLibraryStatus) String() string {
+	return string(s)
+}
+
+// MarshalText implements encoding.TextMarshaler. Like LibraryStatus, statuses
+// that are not valid are marshaled as they are.
+func (s MangaLibraryStatus) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the forms
+// accepted by ParseMangaLibraryStatus.
+func (s *MangaLibraryStatus) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = ""
+		return nil
+	}
+	status, err := ParseMangaLibraryStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Like MarshalText, it marshals
+// statuses that are not valid as they are.
+func (s MangaLibraryStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(s))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Like LibraryStatus, statuses
+// that are not known are kept as they are.
+func (s *MangaLibraryStatus) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	if status, err := ParseMangaLibraryStatus(str); err == nil {
+		*s = status
+		return nil
+	}
+	*s = MangaLibraryStatus(str)
+	return nil
+}
+
+// isMangaMedia reports whether the media of a story or a favorite is a
+// manga. The type of the media is used if there is one, otherwise the
+// attributes that only manga have are looked for.
+func isMangaMedia(mediaType string, media json.RawMessage) bool {
+	if mediaType != "" {
+		return strings.EqualFold(mediaType, MediaTypeManga)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(media, &fields); err != nil {
+		return false
+	}
+	for _, name := range []string{"chapter_count", "volume_count", "manga_type"} {
+		if _, ok := fields[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeMedia decodes media, which is null or an object, into an anime or a
+// manga.
+func decodeMedia(mediaType string, media json.RawMessage) (*Anime, *Manga, error) {
+	if len(media) == 0 || string(media) == "null" {
+		return nil, nil, nil
+	}
+	if isMangaMedia(mediaType, media) {
+		manga := new(Manga)
+		if err := json.Unmarshal(media, manga); err != nil {
+			return nil, nil, err
+		}
+		return nil, manga, nil
+	}
+	anime := new(Anime)
+	if err := json.Unmarshal(media, anime); err != nil {
+		return nil, nil, err
+	}
+	return anime, nil, nil
+}
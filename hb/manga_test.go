@@ -0,0 +1,101 @@
+package hb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseMangaLibraryStatus(t *testing.T) {
+	tests := []struct {
+		in   string
+		want MangaLibraryStatus
+	}{
+		{"currently-reading", StatusCurrentlyReading},
+		{"Reading", StatusCurrentlyReading},
+		{"plan_to_read", StatusPlanToRead},
+		{"On Hold", StatusMangaOnHold},
+		{"completed", StatusMangaCompleted},
+		{"dropped", StatusMangaDropped},
+	}
+	for _, tt := range tests {
+		got, err := ParseMangaLibraryStatus(tt.in)
+		if err != nil {
+			t.Errorf("ParseMangaLibraryStatus(%q) returned error %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseMangaLibraryStatus(%q) is %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := ParseMangaLibraryStatus("watching"); err == nil {
+		t.Error("ParseMangaLibraryStatus(\"watching\") returned no error")
+	}
+}
+
+func TestMangaLibraryStatus_JSON(t *testing.T) {
+	var s MangaLibraryStatus
+	if err := json.Unmarshal([]byte(`"Plan to Read"`), &s); err != nil || s != StatusPlanToRead {
+		t.Errorf("Unmarshal returned %v, %v, want %v, nil", s, err, StatusPlanToRead)
+	}
+
+	// Unknown statuses are kept and marshaled again as they are.
+	if err := json.Unmarshal([]byte(`"rereading"`), &s); err != nil || s != "rereading" {
+		t.Errorf("Unmarshal of unknown status returned %q, %v, want rereading, nil", s, err)
+	}
+	if b, err := json.Marshal(s); err != nil || string(b) != `"rereading"` {
+		t.Errorf("Marshal of unknown status returned %s, %v, want \"rereading\", nil", b, err)
+	}
+	if b, err := s.MarshalText(); err != nil || string(b) != "rereading" {
+		t.Errorf("MarshalText of unknown status returned %s, %v, want rereading, nil", b, err)
+	}
+
+	if err := s.UnmarshalText([]byte("reading")); err != nil || s != StatusCurrentlyReading {
+		t.Errorf("UnmarshalText returned %v, %v, want %v, nil", s, err, StatusCurrentlyReading)
+	}
+	if err := s.UnmarshalText([]byte("watching")); err == nil {
+		t.Error("UnmarshalText of invalid status returned no error")
+	}
+
+	// As text, statuses can be map keys.
+	counts := map[MangaLibraryStatus]int{StatusMangaOnHold: 1}
+	if b, err := json.Marshal(counts); err != nil || string(b) != `{"on-hold":1}` {
+		t.Errorf("Marshal of status map returned %s, %v", b, err)
+	}
+}
+
+func TestFavorite_JSON(t *testing.T) {
+	tests := []struct {
+		data string
+		want Favorite
+	}{
+		{
+			`{"id":1,"item_id":7622,"item_type":"Anime","fav_rank":1}`,
+			Favorite{ID: 1, ItemID: 7622, ItemType: MediaTypeAnime, FavRank: 1},
+		},
+		{
+			`{"id":2,"item_id":1,"item_type":"Anime","item":{"id":1,"slug":"cowboy-bebop"}}`,
+			Favorite{ID: 2, ItemID: 1, ItemType: MediaTypeAnime, Anime: &Anime{ID: 1, Slug: "cowboy-bebop"}},
+		},
+		{
+			`{"id":3,"item_id":14,"item_type":"Manga","item":{"id":14,"slug":"berserk"}}`,
+			Favorite{ID: 3, ItemID: 14, ItemType: MediaTypeManga, Manga: &Manga{ID: 14, Slug: "berserk"}},
+		},
+	}
+	for _, tt := range tests {
+		var got Favorite
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+			t.Fatalf("Unmarshal Favorite %s returned error %v", tt.data, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal Favorite %s is %+v, want %+v", tt.data, got, tt.want)
+		}
+
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("Marshal Favorite returned error %v", err)
+		}
+		if string(b) != tt.data {
+			t.Errorf("Marshal Favorite is %s, want %s", b, tt.data)
+		}
+	}
+}
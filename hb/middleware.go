@@ -0,0 +1,51 @@
+package hb
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call describes an API call that is being made through Client.DoContext.
+type Call struct {
+	// Method is the name of the service method that makes the call, such as
+	// "Anime.Get" or "Library.Update". It is empty when the call is made by
+	// using Client.Do or Client.DoContext directly.
+	Method string
+
+	// Request is the request that will be sent. Middleware can modify it or
+	// replace it before calling the next Handler.
+	Request *http.Request
+
+	// Result is the value the API response is decoded into. It holds the
+	// decoded result once the next Handler has returned without an error.
+	Result interface{}
+}
+
+// Handler makes an API call and decodes its result into call.Result. It
+// returns the API response and error like Client.DoContext.
+type Handler func(ctx context.Context, call *Call) (*http.Response, error)
+
+// Middleware wraps a Handler to intercept the API calls made by a Client. It
+// can inspect or modify the outgoing request before calling next and inspect
+// the response, decoded result and error after next returns. This is useful
+// for logging, metrics, injecting headers or signing requests.
+//
+// For example, a middleware that adds a header to every request:
+//
+//	c.Middleware = append(c.Middleware, func(next hb.Handler) hb.Handler {
+//		return func(ctx context.Context, call *hb.Call) (*http.Response, error) {
+//			call.Request.Header.Set("X-Request-Source", "dashboard")
+//			return next(ctx, call)
+//		}
+//	})
+type Middleware func(next Handler) Handler
+
+// handler returns the Handler that makes the API calls of the Client wrapped
+// by its Middleware. The first Middleware is the outermost one.
+func (c *Client) handler() Handler {
+	h := Handler(c.do)
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		h = c.Middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,92 @@
+package hb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := strings.Join(r.Header["X-Test"], ","), "outer,inner"; got != want {
+			t.Errorf("Request header X-Test is %v, want %v", got, want)
+		}
+		fmt.Fprintf(w, `{"title":"Log Horizon"}`)
+	})
+
+	var order []string
+	var result interface{}
+	tag := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (*http.Response, error) {
+				order = append(order, name+":"+call.Method)
+				call.Request.Header.Add("X-Test", name)
+				resp, err := next(ctx, call)
+				order = append(order, name+":done")
+				result = call.Result
+				return resp, err
+			}
+		}
+	}
+	client.Middleware = []Middleware{tag("outer"), tag("inner")}
+
+	anime, _, err := client.Anime.Get("log-horizon", "")
+	if err != nil {
+		t.Errorf("Anime.Get returned error %v", err)
+	}
+
+	want := []string{"outer:Anime.Get", "inner:Anime.Get", "inner:done", "outer:done"}
+	if got := order; !reflect.DeepEqual(got, want) {
+		t.Errorf("Middleware called in order %v, want %v", got, want)
+	}
+	if got, want := result, interface{}(anime); got != want {
+		t.Errorf("Middleware saw result %v, want %v", got, want)
+	}
+}
+
+func TestClient_Middleware_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/users/TestUser", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	var seen error
+	client.Middleware = []Middleware{func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*http.Response, error) {
+			resp, err := next(ctx, call)
+			seen = err
+			return resp, err
+		}
+	}}
+
+	client.User.Get("TestUser")
+	if !errors.Is(seen, ErrNotFound) {
+		t.Errorf("Middleware saw error %v, want it to match %v", seen, ErrNotFound)
+	}
+}
+
+func TestClient_Middleware_shortCircuit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	errBlocked := errors.New("blocked")
+	client.Middleware = []Middleware{func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*http.Response, error) {
+			return nil, errBlocked
+		}
+	}}
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); err != errBlocked {
+		t.Errorf("Do returned error %v, want %v", err, errBlocked)
+	}
+}
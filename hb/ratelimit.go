@@ -0,0 +1,153 @@
+package hb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket rate limiter that can be set on a Client to
+// limit the rate of the requests it sends. Since all the services of a Client
+// send their requests through Client.Do, the limit is shared by all of them.
+//
+// The bucket holds up to burst tokens and is refilled with limit tokens per
+// second. Each request takes one token and waits if none is available. Both
+// the limit and the burst can be changed while the limiter is in use.
+//
+// A RateLimiter is safe for concurrent use and can also be shared by many
+// clients.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  float64
+	burst  int
+	tokens float64
+	last   time.Time
+	stats  RateLimiterStats
+}
+
+// RateLimiterStats reports how long the requests that passed through a
+// RateLimiter had to wait.
+type RateLimiterStats struct {
+	// Requests is the number of requests that passed through the limiter.
+	Requests int64
+	// Delayed is the number of requests that had to wait.
+	Delayed int64
+	// TotalWait is the total time the requests had to wait.
+	TotalWait time.Duration
+	// MaxWait is the longest time a single request had to wait.
+	MaxWait time.Duration
+}
+
+// NewRateLimiter returns a RateLimiter that allows limit requests per second
+// with bursts of up to burst requests. A limit of zero or less means that
+// requests are not limited. The bucket starts full.
+func NewRateLimiter(limit float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		limit:  limit,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Limit returns the number of requests per second that are allowed.
+func (l *RateLimiter) Limit() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// SetLimit changes the number of requests per second that are allowed. A
+// limit of zero or less means that requests are not limited.
+func (l *RateLimiter) SetLimit(limit float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.limit = limit
+}
+
+// Burst returns the maximum number of requests that can be sent at once.
+func (l *RateLimiter) Burst() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.burst
+}
+
+// SetBurst changes the maximum number of requests that can be sent at once.
+func (l *RateLimiter) SetBurst(burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.burst = burst
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+}
+
+// Stats returns how long the requests that passed through the limiter had to
+// wait so far.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Wait blocks until a request is allowed to be sent or ctx is done and
+// returns the time it waited. If ctx is done first, its error is returned and
+// the request does not count against the limit.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	var wait time.Duration
+	if l.limit > 0 {
+		l.tokens--
+		if l.tokens < 0 {
+			wait = time.Duration(-l.tokens / l.limit * float64(time.Second))
+		}
+	}
+	l.mu.Unlock()
+
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			l.mu.Lock()
+			l.tokens++
+			l.mu.Unlock()
+			return 0, ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	l.mu.Lock()
+	l.stats.Requests++
+	if wait > 0 {
+		l.stats.Delayed++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+	l.mu.Unlock()
+	return wait, nil
+}
+
+// refill adds the tokens accumulated since the last refill. It must be called
+// with l.mu held.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 && l.limit > 0 {
+		l.tokens += elapsed.Seconds() * l.limit
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
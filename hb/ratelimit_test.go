@@ -0,0 +1,111 @@
+package hb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+
+	// The first requests use the initial burst.
+	for i := 0; i < 2; i++ {
+		if wait, err := l.Wait(context.Background()); err != nil || wait != 0 {
+			t.Errorf("Wait #%d returned %v, %v, want 0, nil", i+1, wait, err)
+		}
+	}
+
+	wait, err := l.Wait(context.Background())
+	if err != nil {
+		t.Errorf("Wait returned error %v", err)
+	}
+	if wait <= 0 || wait > 100*time.Millisecond {
+		t.Errorf("Wait after burst waited %v, want between 0 and 100ms", wait)
+	}
+
+	stats := l.Stats()
+	if got, want := stats.Requests, int64(3); got != want {
+		t.Errorf("Stats.Requests is %v, want %v", got, want)
+	}
+	if got, want := stats.Delayed, int64(1); got != want {
+		t.Errorf("Stats.Delayed is %v, want %v", got, want)
+	}
+	if got, want := stats.TotalWait, wait; got != want {
+		t.Errorf("Stats.TotalWait is %v, want %v", got, want)
+	}
+}
+
+func TestRateLimiter_Wait_canceled(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got, want := l.Stats().Requests, int64(1); got != want {
+		t.Errorf("Stats.Requests is %v, want %v", got, want)
+	}
+}
+
+func TestRateLimiter_SetLimit(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	l.Wait(context.Background())
+
+	// Removing the limit at runtime lets the next request through at once.
+	l.SetLimit(0)
+	if wait, err := l.Wait(context.Background()); err != nil || wait != 0 {
+		t.Errorf("Wait without limit returned %v, %v, want 0, nil", wait, err)
+	}
+	if got, want := l.Limit(), 0.0; got != want {
+		t.Errorf("Limit is %v, want %v", got, want)
+	}
+}
+
+func TestRateLimiter_SetBurst(t *testing.T) {
+	l := NewRateLimiter(1, 5)
+	l.SetBurst(1)
+	if got, want := l.Burst(), 1; got != want {
+		t.Errorf("Burst is %v, want %v", got, want)
+	}
+
+	l.Wait(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx); err == nil {
+		t.Error("Expected tokens to be limited by the new burst.")
+	}
+}
+
+func TestClient_Do_rateLimiter(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RateLimiter = NewRateLimiter(50, 1)
+
+	mux.HandleFunc("/api/v1/anime/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"title":"Log Horizon"}`)
+	})
+	mux.HandleFunc("/api/v1/users/TestUser", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name":"TestUser"}`)
+	})
+
+	if _, _, err := client.Anime.Get("log-horizon", ""); err != nil {
+		t.Errorf("Anime.Get returned error %v", err)
+	}
+	if _, _, err := client.User.Get("TestUser"); err != nil {
+		t.Errorf("User.Get returned error %v", err)
+	}
+
+	stats := client.RateLimiter.Stats()
+	if got, want := stats.Requests, int64(2); got != want {
+		t.Errorf("RateLimiter Stats.Requests is %v, want %v", got, want)
+	}
+	if got, want := stats.Delayed, int64(1); got != want {
+		t.Errorf("RateLimiter Stats.Delayed is %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+package hb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rating types of a library entry.
+const (
+	RatingTypeSimple   = "simple"
+	RatingTypeAdvanced = "advanced"
+)
+
+// Simple rating values.
+const (
+	RatingNegative = "negative"
+	RatingNeutral  = "neutral"
+	RatingPositive = "positive"
+)
+
+// simpleRatings are the advanced ratings that the simple ratings convert to.
+// Each one lies within the range of its simple rating.
+var simpleRatings = map[string]Rating{
+	RatingNegative: 1,
+	RatingNeutral:  3,
+	RatingPositive: 4.5,
+}
+
+// Rating is an advanced rating of a library entry, a number from 0 to 5. The
+// API only accepts ratings in steps of 0.5 and a rating of 0 removes the
+// rating of an entry.
+//
+// For conversion between "simple" and "advanced":
+//
+//	0   <= "negative" <= 2.4
+//	2.4 <  "neutral"  <  3.6
+//	3.6 <= "positive" <= 5
+type Rating float64
+
+// NewRating returns a pointer to a Rating with value v, for use with
+// Entry.Rating and Entry.SaneRatingUpdate.
+func NewRating(v float64) *Rating {
+	r := Rating(v)
+	return &r
+}
+
+// ParseRating parses a rating from either its advanced form, a number from 0
+// to 5 such as "4.5", or its simple form, "negative", "neutral" or
+// "positive". Simple ratings are converted to 1, 3 and 4.5 respectively.
+func ParseRating(s string) (Rating, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if r, ok := simpleRatings[text]; ok {
+		return r, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || f < 0 || f > 5 || math.IsNaN(f) {
+		return 0, fmt.Errorf("hb: invalid rating %q", s)
+	}
+	return Rating(f), nil
+}
+
+// Valid reports whether r is a rating that the API accepts, from 0 to 5 in
+// steps of 0.5.
+func (r Rating) Valid() bool {
+	return r >= 0 && r <= 5 && float64(r)*2 == math.Trunc(float64(r)*2)
+}
+
+// validate returns an error if r is neither nil nor valid.
+func (r *Rating) validate() error {
+	if r != nil && !r.Valid() {
+		return fmt.Errorf("hb: invalid rating %v, want 0 to 5 in steps of 0.5", float64(*r))
+	}
+	return nil
+}
+
+// Simple converts r to a simple rating, "negative", "neutral" or "positive".
+func (r Rating) Simple() string {
+	switch {
+	case r <= 2.4:
+		return RatingNegative
+	case r < 3.6:
+		return RatingNeutral
+	}
+	return RatingPositive
+}
+
+// String returns the advanced form of the rating, such as "4.5" or "3".
+func (r Rating) String() string {
+	return strconv.FormatFloat(float64(r), 'f', -1, 64)
+}
+
+// MarshalText implements encoding.TextMarshaler. It fails for ratings that
+// the API does not accept.
+func (r Rating) MarshalText() ([]byte, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the forms
+// accepted by ParseRating.
+func (r *Rating) UnmarshalText(text []byte) error {
+	v, err := ParseRating(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. The rating is encoded as a string,
+// the way the API expects it. It fails for ratings that the API does not
+// accept.
+func (r Rating) MarshalJSON() ([]byte, error) {
+	text, err := r.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts both numbers and
+// strings in the forms accepted by ParseRating.
+func (r *Rating) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	s, err := ratingText(data)
+	if err != nil {
+		return err
+	}
+	return r.UnmarshalText([]byte(s))
+}
+
+// ratingText returns the text of a rating that the API encoded either as a
+// JSON number or as a JSON string.
+func ratingText(data []byte) (string, error) {
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(string(data)))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("hb: invalid rating %s", data)
+}
+
+// LibraryEntryRating represents the rating of a user's library entry.
+// The representation it's value depends on the type which can be either
+// "simple" or "advanced".
+//
+// If type is "simple", the value can be "negative", "neutral" or "positive".
+// If type is "advanced", the value can be a number between "0.0" and "5.0".
+// The API returns advanced values either as strings or as numbers and both
+// are decoded into Value as text.
+//
+// Use the Rating method to get the value as a Rating, converted to advanced
+// if needed, and Rating.Simple to convert it to simple.
+type LibraryEntryRating struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts the value as either
+// a string or a number.
+func (r *LibraryEntryRating) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Type = raw.Type
+	r.Value = ""
+	if len(raw.Value) > 0 {
+		value, err := ratingText(raw.Value)
+		if err != nil {
+			return err
+		}
+		r.Value = value
+	}
+	return nil
+}
+
+// Rating returns the value of the rating as an advanced Rating. Simple
+// ratings are converted as documented on ParseRating.
+func (r *LibraryEntryRating) Rating() (Rating, error) {
+	return ParseRating(r.Value)
+}
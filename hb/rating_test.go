@@ -0,0 +1,155 @@
+package hb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rating
+	}{
+		{"0", 0},
+		{"3.5", 3.5},
+		{"4.0", 4},
+		{" 5 ", 5},
+		{"negative", 1},
+		{"Neutral", 3},
+		{"positive", 4.5},
+	}
+	for _, tt := range tests {
+		got, err := ParseRating(tt.in)
+		if err != nil {
+			t.Errorf("ParseRating(%q) returned error %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRating(%q) is %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "5.5", "-1", "great", "NaN"} {
+		if _, err := ParseRating(in); err == nil {
+			t.Errorf("ParseRating(%q) expected invalid rating error.", in)
+		}
+	}
+}
+
+func TestRating_Valid(t *testing.T) {
+	for r, want := range map[Rating]bool{0: true, 0.5: true, 4.5: true, 5: true, 4.3: false, 5.5: false, -0.5: false} {
+		if got := r.Valid(); got != want {
+			t.Errorf("Rating(%v).Valid() is %v, want %v", float64(r), got, want)
+		}
+	}
+}
+
+func TestRating_Simple(t *testing.T) {
+	tests := []struct {
+		r    Rating
+		want string
+	}{
+		{0, RatingNegative},
+		{2.4, RatingNegative},
+		{2.5, RatingNeutral},
+		{3.5, RatingNeutral},
+		{3.6, RatingPositive},
+		{5, RatingPositive},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Simple(); got != tt.want {
+			t.Errorf("Rating(%v).Simple() is %q, want %q", tt.r, got, tt.want)
+		}
+		// Simple ratings must convert back to the same simple rating.
+		r, _ := ParseRating(tt.want)
+		if got := r.Simple(); got != tt.want {
+			t.Errorf("ParseRating(%q).Simple() is %q", tt.want, got)
+		}
+	}
+}
+
+func TestRating_JSON(t *testing.T) {
+	b, err := json.Marshal(Entry{SaneRatingUpdate: NewRating(4.5), Rating: NewRating(0)})
+	if err != nil {
+		t.Fatalf("Marshal returned error %v", err)
+	}
+	want := `{"id":"","auth_token":"","rating":"0","sane_rating_update":"4.5"}`
+	if got := string(b); got != want {
+		t.Errorf("Marshal is %s, want %s", got, want)
+	}
+
+	if _, err := json.Marshal(Entry{Rating: NewRating(4.3)}); err == nil {
+		t.Error("Expected invalid rating error.")
+	}
+
+	var r Rating
+	for _, in := range []string{`3.5`, `"3.5"`, `"neutral"`} {
+		if err := json.Unmarshal([]byte(in), &r); err != nil {
+			t.Errorf("Unmarshal(%s) returned error %v", in, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`true`), &r); err == nil {
+		t.Error("Expected invalid rating error.")
+	}
+}
+
+func TestLibraryEntryRating_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want LibraryEntryRating
+		r    Rating
+	}{
+		{`{"type":"advanced","value":"4.0"}`, LibraryEntryRating{Type: RatingTypeAdvanced, Value: "4.0"}, 4},
+		{`{"type":"advanced","value":3.5}`, LibraryEntryRating{Type: RatingTypeAdvanced, Value: "3.5"}, 3.5},
+		{`{"type":"simple","value":"positive"}`, LibraryEntryRating{Type: RatingTypeSimple, Value: RatingPositive}, 4.5},
+	}
+	for _, tt := range tests {
+		var got LibraryEntryRating
+		if err := json.Unmarshal([]byte(tt.in), &got); err != nil {
+			t.Errorf("Unmarshal(%s) returned error %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) is %+v, want %+v", tt.in, got, tt.want)
+		}
+		if r, err := got.Rating(); err != nil || r != tt.r {
+			t.Errorf("Rating of %s is %v, %v, want %v", tt.in, r, err, tt.r)
+		}
+	}
+}
+
+func TestLibraryService_Update_rating(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		testBody(t, r, `{"id":"log-horizon","auth_token":"valid_user_token","sane_rating_update":"3.5"}`+"\n")
+		fmt.Fprintf(w, `{"id":7622,"rating":{"type":"advanced","value":3.5}}`)
+	})
+
+	e, _, err := client.Library.Update("log-horizon", "valid_user_token", &Entry{SaneRatingUpdate: NewRating(3.5)})
+	if err != nil {
+		t.Fatalf("Library.Update returned error %v", err)
+	}
+	if got, want := *e.Rating, (LibraryEntryRating{Type: RatingTypeAdvanced, Value: "3.5"}); got != want {
+		t.Errorf("Library.Update rating is %+v, want %+v", got, want)
+	}
+}
+
+func TestLibraryService_Update_invalidRating(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request sent with invalid rating.")
+	})
+
+	_, resp, err := client.Library.Update("log-horizon", "valid_user_token", &Entry{SaneRatingUpdate: NewRating(3.7)})
+	if err == nil {
+		t.Error("Expected invalid rating error.")
+	}
+	if resp != nil {
+		t.Error("Expected nil HTTP response when rating is invalid.")
+	}
+}
@@ -0,0 +1,359 @@
+// Package recommend suggests anime to a user from the libraries and favorite
+// anime of users that were already fetched from the API, with
+// UserService.Library and UserService.FavoriteAnime. It doesn't access the
+// network.
+//
+// Suggestions combine two scores:
+//
+// The genre score is how much the user likes the genres of an anime, from the
+// ratings the user gave to anime of the same genres.
+//
+// The collaborative score is how much the users with the most similar
+// ratings liked an anime (user-user collaborative filtering).
+//
+// The API doesn't include the genres of the anime in library entries, so the
+// genre score needs anime with genres, such as the ones returned by
+// AnimeService.Get or UserService.FavoriteAnime, added with Engine.AddAnime.
+package recommend
+
+import (
+	"math"
+	"sort"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// neutral is the rating, in the middle of the range of ratings, that means
+// that a user neither likes nor dislikes an anime. Preferences are relative
+// to it.
+const neutral = 2.5
+
+// favoritePreference is the preference of a user for a favorite anime.
+const favoritePreference = 5
+
+// statusPreferences are the preferences for the anime of library entries that
+// are not rated, depending on their status. Entries planned to watch say
+// nothing about the user's taste and are not included.
+var statusPreferences = map[hb.LibraryStatus]float64{
+	hb.StatusCompleted:         3.5,
+	hb.StatusCurrentlyWatching: 3.5,
+	hb.StatusOnHold:            2.5,
+	hb.StatusDropped:           1,
+}
+
+// Profile is what is known about the taste of a user.
+type Profile struct {
+	// Name is the name of the user, which identifies the profile.
+	Name string
+
+	// Library is the library of the user.
+	Library []hb.LibraryEntry
+
+	// Favorites are the favorite anime of the user.
+	Favorites []hb.Anime
+}
+
+// Options configure a recommendation. Zero fields take the value of the
+// field in DefaultOptions.
+type Options struct {
+	// Limit is the maximum number of recommendations. A negative Limit
+	// means no limit.
+	Limit int
+
+	// GenreWeight and CollaborativeWeight are the weights of the genre and
+	// collaborative scores in the score of a recommendation. A negative
+	// weight leaves the score out.
+	GenreWeight         float64
+	CollaborativeWeight float64
+
+	// Neighbors is the number of the most similar users that the
+	// collaborative score is computed from.
+	Neighbors int
+
+	// MinOverlap is the number of anime that another user must have in
+	// common with the user to be considered similar.
+	MinOverlap int
+}
+
+// DefaultOptions returns the options that Recommend uses for the zero fields
+// of the Options it is given.
+func DefaultOptions() Options {
+	return Options{
+		Limit:               10,
+		GenreWeight:         0.3,
+		CollaborativeWeight: 0.7,
+		Neighbors:           20,
+		MinOverlap:          2,
+	}
+}
+
+// withDefaults returns o with its zero fields set to their defaults and its
+// negative fields set to zero.
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.Limit == 0 {
+		o.Limit = d.Limit
+	} else if o.Limit < 0 {
+		o.Limit = 0
+	}
+	if o.GenreWeight == 0 {
+		o.GenreWeight = d.GenreWeight
+	} else if o.GenreWeight < 0 {
+		o.GenreWeight = 0
+	}
+	if o.CollaborativeWeight == 0 {
+		o.CollaborativeWeight = d.CollaborativeWeight
+	} else if o.CollaborativeWeight < 0 {
+		o.CollaborativeWeight = 0
+	}
+	if o.Neighbors == 0 {
+		o.Neighbors = d.Neighbors
+	} else if o.Neighbors < 0 {
+		o.Neighbors = 0
+	}
+	if o.MinOverlap == 0 {
+		o.MinOverlap = d.MinOverlap
+	}
+	return o
+}
+
+// Recommendation is an anime suggested to a user.
+type Recommendation struct {
+	Anime *hb.Anime `json:"anime"`
+
+	// Score is the weighted combination of GenreScore and
+	// CollaborativeScore. All scores are relative to a neutral preference
+	// and range from -2.5 to 2.5, like ratings from 0 to 5.
+	Score              float64 `json:"score"`
+	GenreScore         float64 `json:"genre_score"`
+	CollaborativeScore float64 `json:"collaborative_score"`
+
+	// Neighbors are the names of the similar users who like the anime.
+	Neighbors []string `json:"neighbors,omitempty"`
+}
+
+// Engine recommends anime from the profiles and the anime added to it. An
+// Engine is not safe for concurrent use.
+type Engine struct {
+	anime    map[int]*hb.Anime
+	profiles map[string]*profile
+}
+
+// profile holds the preferences of a user by anime ID, relative to neutral.
+type profile struct {
+	name        string
+	preferences map[int]float64
+}
+
+// NewEngine returns an Engine without any profiles or anime.
+func NewEngine() *Engine {
+	return &Engine{
+		anime:    make(map[int]*hb.Anime),
+		profiles: make(map[string]*profile),
+	}
+}
+
+// AddAnime adds anime to the engine, so that their genres are known. Anime
+// that are added again replace the previous ones, unless they have no genres
+// and the previous ones do.
+func (e *Engine) AddAnime(anime ...hb.Anime) {
+	for i := range anime {
+		a := anime[i]
+		if old, ok := e.anime[a.ID]; ok && len(a.Genres) == 0 && len(old.Genres) > 0 {
+			continue
+		}
+		e.anime[a.ID] = &a
+	}
+}
+
+// AddProfile adds the profile of a user, whose library is used to
+// recommend anime to other users. Adding a profile with the same name again
+// replaces it. The anime of the profile are added with AddAnime.
+func (e *Engine) AddProfile(p Profile) {
+	e.addProfileAnime(p)
+	e.profiles[p.Name] = newProfile(p)
+}
+
+func (e *Engine) addProfileAnime(p Profile) {
+	for _, le := range p.Library {
+		if le.Anime != nil {
+			e.AddAnime(*le.Anime)
+		}
+	}
+	e.AddAnime(p.Favorites...)
+}
+
+// newProfile computes the preferences of a user. Ratings are used as they
+// are, unrated entries have a preference depending on their status and
+// favorite anime have the highest preference.
+func newProfile(p Profile) *profile {
+	prefs := make(map[int]float64)
+	for _, le := range p.Library {
+		if le.Anime == nil {
+			continue
+		}
+		pref, ok := statusPreferences[le.Status]
+		if le.Rating != nil {
+			if r, err := le.Rating.Rating(); err == nil && r > 0 {
+				pref, ok = float64(r), true
+			}
+		}
+		if ok {
+			prefs[le.Anime.ID] = pref - neutral
+		}
+	}
+	for _, a := range p.Favorites {
+		prefs[a.ID] = favoritePreference - neutral
+	}
+	return &profile{name: p.Name, preferences: prefs}
+}
+
+// similarity returns the cosine similarity of the preferences of two users
+// over the anime they have in common, and the number of those anime.
+func (p *profile) similarity(q *profile) (float64, int) {
+	var dot, np, nq float64
+	overlap := 0
+	for id, a := range p.preferences {
+		b, ok := q.preferences[id]
+		if !ok {
+			continue
+		}
+		overlap++
+		dot += a * b
+		np += a * a
+		nq += b * b
+	}
+	if np == 0 || nq == 0 {
+		return 0, overlap
+	}
+	return dot / math.Sqrt(np*nq), overlap
+}
+
+// neighbor is a similar user.
+type neighbor struct {
+	*profile
+	similarity float64
+}
+
+// neighbors returns the opts.Neighbors users most similar to p.
+func (e *Engine) neighbors(p *profile, opts Options) []neighbor {
+	var ns []neighbor
+	for name, q := range e.profiles {
+		if name == p.name {
+			continue
+		}
+		sim, overlap := p.similarity(q)
+		if overlap < opts.MinOverlap || sim <= 0 {
+			continue
+		}
+		ns = append(ns, neighbor{q, sim})
+	}
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].similarity != ns[j].similarity {
+			return ns[i].similarity > ns[j].similarity
+		}
+		return ns[i].name < ns[j].name
+	})
+	if len(ns) > opts.Neighbors {
+		ns = ns[:opts.Neighbors]
+	}
+	return ns
+}
+
+// genreAffinities returns the affinity of the user for each genre, the sum
+// of the preferences for anime of the genre divided by their number plus
+// one, so that genres seen only a few times weigh less.
+func (e *Engine) genreAffinities(p *profile) map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for id, pref := range p.preferences {
+		a, ok := e.anime[id]
+		if !ok {
+			continue
+		}
+		for _, g := range a.Genres {
+			sums[g.Name] += pref
+			counts[g.Name]++
+		}
+	}
+	affinities := make(map[string]float64, len(sums))
+	for g, sum := range sums {
+		affinities[g] = sum / float64(counts[g]+1)
+	}
+	return affinities
+}
+
+// Recommend returns the anime, among the ones the engine knows, that the
+// user is most likely to like, best first. Anime in the library or the
+// favorites of the user are not recommended and neither are anime with a
+// score of zero or less.
+//
+// The user doesn't need to be added to the engine but the anime of the user
+// are added with AddAnime. The zero Options can be given for DefaultOptions.
+func (e *Engine) Recommend(user Profile, opts Options) []Recommendation {
+	opts = opts.withDefaults()
+	e.addProfileAnime(user)
+	p := newProfile(user)
+	seen := make(map[int]bool, len(user.Library))
+	for _, le := range user.Library {
+		if le.Anime != nil {
+			seen[le.Anime.ID] = true
+		}
+	}
+	for id := range p.preferences {
+		seen[id] = true
+	}
+
+	affinities := e.genreAffinities(p)
+	neighbors := e.neighbors(p, opts)
+	weights := opts.GenreWeight + opts.CollaborativeWeight
+	if weights <= 0 {
+		return nil
+	}
+
+	var recs []Recommendation
+	for id, a := range e.anime {
+		if seen[id] {
+			continue
+		}
+		rec := Recommendation{Anime: a}
+		if len(a.Genres) > 0 {
+			for _, g := range a.Genres {
+				rec.GenreScore += affinities[g.Name]
+			}
+			rec.GenreScore /= float64(len(a.Genres))
+		}
+
+		var sum, sims float64
+		for _, n := range neighbors {
+			pref, ok := n.preferences[id]
+			if !ok {
+				continue
+			}
+			sum += n.similarity * pref
+			sims += n.similarity
+			if pref > 0 {
+				rec.Neighbors = append(rec.Neighbors, n.name)
+			}
+		}
+		if sims > 0 {
+			rec.CollaborativeScore = sum / sims
+		}
+
+		rec.Score = (opts.GenreWeight*rec.GenreScore + opts.CollaborativeWeight*rec.CollaborativeScore) / weights
+		if rec.Score > 0 {
+			recs = append(recs, rec)
+		}
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Score != recs[j].Score {
+			return recs[i].Score > recs[j].Score
+		}
+		return recs[i].Anime.ID < recs[j].Anime.ID
+	})
+	if opts.Limit > 0 && len(recs) > opts.Limit {
+		recs = recs[:opts.Limit]
+	}
+	return recs
+}
@@ -0,0 +1,161 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nstratos/go-hummingbird/hb"
+	"github.com/nstratos/go-hummingbird/hb/hbtest"
+)
+
+// Anime IDs of the default fixtures of hbtest.
+const (
+	logHorizon  = 7622
+	nichijou    = 5766
+	anohana     = 5874
+	cowboyBebop = 1
+	kiznaiver   = 11253
+)
+
+// rated returns library entries with the given ratings by anime ID. The
+// anime have no genres, like the ones the API returns.
+func rated(ratings map[int]hb.Rating) []hb.LibraryEntry {
+	var entries []hb.LibraryEntry
+	for id, r := range ratings {
+		entries = append(entries, hb.LibraryEntry{
+			Status: hb.StatusCompleted,
+			Anime:  &hb.Anime{ID: id},
+			Rating: &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: r.String()},
+		})
+	}
+	return entries
+}
+
+func newTestEngine() *Engine {
+	e := NewEngine()
+	e.AddAnime(hbtest.DefaultFixtures().Anime...)
+	e.AddProfile(Profile{Name: "alice", Library: rated(map[int]hb.Rating{
+		logHorizon: 5, nichijou: 1, cowboyBebop: 5, anohana: 1,
+	})})
+	e.AddProfile(Profile{Name: "bob", Library: rated(map[int]hb.Rating{
+		logHorizon: 1, nichijou: 5, cowboyBebop: 1, anohana: 5,
+	})})
+	return e
+}
+
+func ids(recs []Recommendation) []int {
+	var ids []int
+	for _, r := range recs {
+		ids = append(ids, r.Anime.ID)
+	}
+	return ids
+}
+
+func TestRecommend(t *testing.T) {
+	e := newTestEngine()
+	me := Profile{Name: "me", Library: rated(map[int]hb.Rating{logHorizon: 5, nichijou: 1})}
+
+	recs := e.Recommend(me, Options{})
+	if got, want := ids(recs), []int{cowboyBebop}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Recommend returned %v, want %v", got, want)
+	}
+	bebop := recs[0]
+	if bebop.Anime.Title != "Cowboy Bebop" {
+		t.Errorf("Recommended anime is %+v, want the anime with genres", bebop.Anime)
+	}
+	if bebop.GenreScore <= 0 || bebop.CollaborativeScore != 2.5 {
+		t.Errorf("Recommendation scores are %v and %v, want positive and 2.5", bebop.GenreScore, bebop.CollaborativeScore)
+	}
+	if got, want := bebop.Neighbors, []string{"alice"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Recommendation neighbors are %v, want %v", got, want)
+	}
+}
+
+func TestRecommend_favorites(t *testing.T) {
+	e := newTestEngine()
+	// Favorites count as the highest preference and are not recommended. The
+	// user likes what bob likes and Kiznaiver shares Drama with Anohana.
+	me := Profile{
+		Name:      "me",
+		Library:   rated(map[int]hb.Rating{nichijou: 5}),
+		Favorites: []hb.Anime{{ID: anohana}},
+	}
+
+	recs := e.Recommend(me, Options{})
+	if got, want := ids(recs), []int{kiznaiver}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Recommend returned %v, want %v", got, want)
+	}
+	if recs[0].GenreScore <= 0 || recs[0].CollaborativeScore != 0 || recs[0].Neighbors != nil {
+		t.Errorf("Recommendation is %+v, want only a genre score", recs[0])
+	}
+}
+
+func TestRecommend_genresOnly(t *testing.T) {
+	e := NewEngine()
+	e.AddAnime(hbtest.DefaultFixtures().Anime...)
+	me := Profile{Name: "me", Library: rated(map[int]hb.Rating{logHorizon: 5, kiznaiver: 1})}
+
+	// Cowboy Bebop shares Action with Log Horizon, which the user likes, more
+	// than Sci-Fi with Kiznaiver, which the user doesn't. Anohana only shares
+	// Drama with Kiznaiver.
+	recs := e.Recommend(me, Options{})
+	if got, want := ids(recs), []int{cowboyBebop}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Recommend returned %v, want %v", got, want)
+	}
+	if got, want := recs[0].Score, 0.3*(1.25-0.75)/2; got != want {
+		t.Errorf("Recommendation score is %v, want %v", got, want)
+	}
+}
+
+func TestRecommend_limit(t *testing.T) {
+	e := newTestEngine()
+	me := Profile{Name: "me", Library: rated(map[int]hb.Rating{logHorizon: 5, nichijou: 5})}
+
+	opts := DefaultOptions()
+	opts.Limit = 1
+	if recs := e.Recommend(me, opts); len(recs) != 1 {
+		t.Errorf("Recommend returned %v recommendations, want 1", len(recs))
+	}
+}
+
+func TestRecommend_partialOptions(t *testing.T) {
+	e := newTestEngine()
+	me := Profile{Name: "me", Library: rated(map[int]hb.Rating{logHorizon: 5, nichijou: 5})}
+
+	// The fields that are not set take their defaults, so the
+	// recommendations are the default ones up to the limit.
+	want := ids(e.Recommend(me, DefaultOptions()))
+	if len(want) < 2 {
+		t.Fatalf("Recommend returned %v, want at least 2 recommendations", want)
+	}
+	if got := ids(e.Recommend(me, Options{Limit: 1})); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("Recommend with Limit 1 returned %v, want %v", got, want[:1])
+	}
+	if got := ids(e.Recommend(me, Options{Limit: -1})); !reflect.DeepEqual(got, want) {
+		t.Errorf("Recommend without limit returned %v, want %v", got, want)
+	}
+}
+
+func TestRecommend_minOverlap(t *testing.T) {
+	e := newTestEngine()
+	me := Profile{Name: "me", Library: rated(map[int]hb.Rating{logHorizon: 5})}
+
+	opts := DefaultOptions()
+	opts.GenreWeight = -1
+	if recs := e.Recommend(me, opts); len(recs) != 0 {
+		t.Errorf("Recommend returned %v with one anime in common, want none", ids(recs))
+	}
+	opts.MinOverlap = 1
+	if recs := e.Recommend(me, opts); len(recs) == 0 || recs[0].Anime.ID != cowboyBebop {
+		t.Errorf("Recommend returned %v, want %v first", ids(recs), cowboyBebop)
+	}
+}
+
+func TestEngine_AddAnime(t *testing.T) {
+	e := NewEngine()
+	e.AddAnime(hb.Anime{ID: 1, Title: "Cowboy Bebop", Genres: []hb.Genre{{Name: "Action"}}})
+	e.AddAnime(hb.Anime{ID: 1, Title: "Cowboy Bebop"})
+	if got := e.anime[1]; len(got.Genres) != 1 {
+		t.Errorf("Anime without genres replaced %+v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package hb
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how Client retries requests that failed because of a
+// transient error. A request is retried if it failed with a connection error
+// (such as a connection reset) or if the API responded with one of the
+// StatusCodes, as long as its method is one of the Methods.
+//
+// Retrying a request that is not idempotent can apply the same change twice.
+// For that reason POST, which is used by LibraryService.Update,
+// LibraryService.Remove and UserService.Authenticate, is not retried unless
+// it is explicitly added to Methods.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including
+	// the first attempt. A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the time to wait before the first retry. The wait is
+	// doubled after each retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the upper limit of the wait between two attempts. If it
+	// is zero, the wait is not limited. A request whose Retry-After asks for
+	// a longer wait is not retried and its response is returned.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction, between 0 and 1, by which the wait between two
+	// attempts is randomly reduced so that many clients do not retry at the
+	// same time.
+	Jitter float64
+
+	// StatusCodes are the response status codes that are considered
+	// transient.
+	StatusCodes []int
+
+	// Methods are the request methods that can be retried.
+	Methods []string
+
+	// IgnoreRetryAfter disables waiting for the duration requested by the
+	// Retry-After header of the response when one is present.
+	IgnoreRetryAfter bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy which makes up to 3 attempts,
+// waits between 500ms and 10s between them and retries the idempotent
+// requests that failed with a connection error, 429, 500, 502, 503 or 504.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Jitter:      0.5,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Methods: []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE"},
+	}
+}
+
+// retry reports whether a request should be sent again after the given
+// attempt returned resp and err, and how long to wait before doing so.
+func (p *RetryPolicy) retry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if !p.retryMethod(req.Method) || !canReplay(req) {
+		return 0, false
+	}
+	switch {
+	case err != nil:
+		if !isTransient(err) {
+			return 0, false
+		}
+	case resp != nil:
+		if !p.retryStatus(resp.StatusCode) {
+			return 0, false
+		}
+		if d, ok := retryAfter(resp); ok && !p.IgnoreRetryAfter {
+			if p.MaxBackoff > 0 && d > p.MaxBackoff {
+				return 0, false
+			}
+			return d, true
+		}
+	}
+	return p.backoff(attempt), true
+}
+
+func (p *RetryPolicy) retryMethod(method string) bool {
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryStatus(code int) bool {
+	for _, c := range p.StatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the time to wait after the given attempt failed.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if j := p.Jitter; j > 0 && d > 0 {
+		if j > 1 {
+			j = 1
+		}
+		d -= time.Duration(rand.Float64() * j * float64(d))
+	}
+	return d
+}
+
+// canReplay reports whether the body of req can be sent again.
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isTransient reports whether err is a connection error that might not happen
+// if the request is sent again.
+func isTransient(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// retryAfter parses the Retry-After header of resp which can either be a
+// number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
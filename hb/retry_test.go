@@ -0,0 +1,248 @@
+package hb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy returns a retry policy with short deterministic waits.
+func testRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.MinBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	p.Jitter = 0
+	return p
+}
+
+func TestClient_Do_retry(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = testRetryPolicy()
+
+	calls := 0
+	mux.HandleFunc("/foo", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			http.Error(w, `{"error":"unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"Foo":"bar"}`)
+	})
+
+	req, _ := client.NewRequest("GET", "/foo", nil)
+	body := new(struct{ Foo string })
+	_, err := client.Do(req, body)
+	if err != nil {
+		t.Errorf("Do returned error %v", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("Do sent %v requests, want %v", got, want)
+	}
+	if got, want := body.Foo, "bar"; got != want {
+		t.Errorf("Do body.Foo is %v, want %v", got, want)
+	}
+}
+
+func TestClient_Do_retryMaxAttempts(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = testRetryPolicy()
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, `{"error":"Bad Gateway"}`, http.StatusBadGateway)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	resp, err := client.Do(req, nil)
+	if err == nil {
+		t.Error("Expected HTTP 502 error.")
+	}
+	if got, want := err.Error(), fmt.Sprintf("GET %v/: 502 Bad Gateway", server.URL); got != want {
+		t.Errorf("ErrorResponse is %v, want %v", got, want)
+	}
+	if resp == nil {
+		t.Error("Expected to return HTTP response of the last attempt.")
+	}
+	if got, want := calls, client.Retry.MaxAttempts; got != want {
+		t.Errorf("Do sent %v requests, want %v", got, want)
+	}
+}
+
+func TestClient_Do_retryStatusNotRetryable(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = testRetryPolicy()
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, `{"error":"Bad Request"}`, http.StatusBadRequest)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); err == nil {
+		t.Error("Expected HTTP 400 error.")
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("Do sent %v requests, want %v", got, want)
+	}
+}
+
+func TestLibraryService_Update_notRetried(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = testRetryPolicy()
+
+	calls := 0
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, `{"error":"unavailable"}`, http.StatusServiceUnavailable)
+	})
+
+	if _, _, err := client.Library.Update("log-horizon", "valid_user_token", nil); err == nil {
+		t.Error("Expected HTTP 503 error.")
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("Library.Update sent %v requests, want %v", got, want)
+	}
+}
+
+func TestLibraryService_Update_retryPOSTEnabled(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = testRetryPolicy()
+	client.Retry.Methods = append(client.Retry.Methods, "POST")
+
+	calls := 0
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		// The body must be sent again on every attempt.
+		testBody(t, r, `{"id":"log-horizon","auth_token":"valid_user_token","status":"currently-watching"}`+"\n")
+		if calls == 1 {
+			http.Error(w, `{"error":"unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"id":7622}`)
+	})
+
+	if _, _, err := client.Library.Update("log-horizon", "valid_user_token", nil); err != nil {
+		t.Errorf("Library.Update returned error %v", err)
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("Library.Update sent %v requests, want %v", got, want)
+	}
+}
+
+func TestClient_Do_retryConnectionError(t *testing.T) {
+	setup()
+	client.Retry = testRetryPolicy()
+	// Closing the server makes every attempt fail with a connection error.
+	teardown()
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, nil)
+	if err == nil {
+		t.Error("Expected connection refused error.")
+	}
+	if !isTransient(err) {
+		t.Errorf("Expected connection error to be transient, got %v", err)
+	}
+}
+
+func TestRetryPolicy_retryAfter(t *testing.T) {
+	p := testRetryPolicy()
+	p.MaxBackoff = 10 * time.Second
+	req, _ := http.NewRequest("GET", "/", nil)
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "2")
+
+	wait, ok := p.retry(1, req, resp, nil)
+	if !ok {
+		t.Fatal("Expected 429 response to be retried.")
+	}
+	if got, want := wait, 2*time.Second; got != want {
+		t.Errorf("retry wait is %v, want %v", got, want)
+	}
+
+	p.IgnoreRetryAfter = true
+	if wait, _ := p.retry(1, req, resp, nil); wait != p.MinBackoff {
+		t.Errorf("retry wait ignoring Retry-After is %v, want %v", wait, p.MinBackoff)
+	}
+}
+
+func TestRetryPolicy_retryAfterTooLong(t *testing.T) {
+	p := testRetryPolicy()
+	p.MaxBackoff = 10 * time.Second
+	req, _ := http.NewRequest("GET", "/", nil)
+
+	for _, after := range []string{"86400", time.Now().Add(24 * time.Hour).UTC().Format(http.TimeFormat)} {
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+		resp.Header.Set("Retry-After", after)
+		if wait, ok := p.retry(1, req, resp, nil); ok {
+			t.Errorf("retry with Retry-After %v waits %v, want no retry", after, wait)
+		}
+	}
+
+	// Without a MaxBackoff the wait is not limited.
+	p.MaxBackoff = 0
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"86400"}}}
+	if wait, ok := p.retry(1, req, resp, nil); !ok || wait != 24*time.Hour {
+		t.Errorf("retry without MaxBackoff is %v, %v, want %v, true", wait, ok, 24*time.Hour)
+	}
+}
+
+func TestClient_Do_retryAfterTooLong(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = testRetryPolicy()
+
+	requests := 0
+	mux.HandleFunc("/api/v1/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "86400")
+		http.Error(w, `{"error":"slow down"}`, http.StatusTooManyRequests)
+	})
+
+	_, resp, err := client.Anime.Get("1", "")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Anime.Get returned error %v, want %v", err, ErrRateLimited)
+	}
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Anime.Get returned response %v, want the 429 response", resp)
+	}
+	if requests != 1 {
+		t.Errorf("Request was sent %v times, want 1", requests)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{10, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) is %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		if got := p.backoff(1); got < p.MinBackoff/2 || got > p.MinBackoff {
+			t.Errorf("backoff(1) with jitter is %v, want between %v and %v", got, p.MinBackoff/2, p.MinBackoff)
+		}
+	}
+}
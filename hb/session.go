@@ -0,0 +1,174 @@
+package hb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrNoSession is returned by the methods that use the Session of a Client
+// when the Client has none.
+var ErrNoSession = errors.New("hb: client has no session")
+
+// Credentials are the values used to authenticate a user. From Username and
+// Email only one is needed.
+type Credentials struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// Credentials implements CredentialSource by returning the credentials as is.
+func (c Credentials) Credentials(ctx context.Context) (Credentials, error) {
+	return c, nil
+}
+
+// CredentialSource provides the credentials of a user when a Session needs to
+// authenticate. Implementations can read them from configuration, a secret
+// store or prompt the user.
+type CredentialSource interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// Session holds the authentication token of a user so that it doesn't have to
+// be passed to every method that requires authentication. When the API
+// rejects the token, the Session authenticates again with the credentials of
+// its CredentialSource.
+//
+// A Session is safe for concurrent use.
+type Session struct {
+	source CredentialSource
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewSession returns a Session that gets a token by authenticating with the
+// credentials provided by source. If token is not empty, it is used until the
+// API rejects it. If source is nil, the Session can only use token.
+func NewSession(token string, source CredentialSource) *Session {
+	return &Session{token: token, source: source}
+}
+
+// Token returns the current authentication token of the session, which is
+// empty if the session has not authenticated yet.
+func (s *Session) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// authToken returns the token of the session, authenticating first if the
+// session has none.
+func (s *Session) authToken(ctx context.Context, c *Client) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" {
+		return s.token, nil
+	}
+	return s.authenticate(ctx, c)
+}
+
+// refresh authenticates again unless the token has already changed since
+// stale was returned.
+func (s *Session) refresh(ctx context.Context, c *Client, stale string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != stale {
+		return s.token, nil
+	}
+	return s.authenticate(ctx, c)
+}
+
+// authenticate must be called with s.mu held.
+func (s *Session) authenticate(ctx context.Context, c *Client) (string, error) {
+	if s.source == nil {
+		return "", ErrUnauthorized
+	}
+	cred, err := s.source.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	token, _, err := c.User.AuthenticateContext(ctx, cred.Username, cred.Email, cred.Password)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token, nil
+}
+
+// withSession calls fn with the token of the Session of the Client. If the API
+// rejects the token, the session authenticates again and fn is called once
+// more with the new token.
+func (c *Client) withSession(ctx context.Context, fn func(token string) (*http.Response, error)) (*http.Response, error) {
+	s := c.Session
+	if s == nil {
+		return nil, ErrNoSession
+	}
+	token, err := s.authToken(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fn(token)
+	if !errors.Is(err, ErrUnauthorized) || s.source == nil {
+		return resp, err
+	}
+	token, err = s.refresh(ctx, c, token)
+	if err != nil {
+		return nil, err
+	}
+	return fn(token)
+}
+
+// Login authenticates the user whose credentials are provided by source and
+// stores the token in a new Session of the Client. Methods such as
+// LibraryService.UpdateWithSession then use that token and authenticate again
+// through source when the API rejects it.
+func (s *UserService) Login(ctx context.Context, source CredentialSource) error {
+	session := NewSession("", source)
+	if _, err := session.authToken(ctx, s.client); err != nil {
+		return err
+	}
+	s.client.Session = session
+	return nil
+}
+
+// UpdateWithSession is like UpdateContext but uses the token of the Session of
+// the Client. It returns ErrNoSession if the Client has no session.
+func (s *LibraryService) UpdateWithSession(ctx context.Context, animeID string, entry *Entry) (*LibraryEntry, *http.Response, error) {
+	var libraryEntry *LibraryEntry
+	resp, err := s.client.withSession(ctx, func(token string) (*http.Response, error) {
+		// UpdateContext sets the ID and token of the entry so a copy is used
+		// on each attempt.
+		var e *Entry
+		if entry != nil {
+			e = new(Entry)
+			*e = *entry
+		}
+		var resp *http.Response
+		var err error
+		libraryEntry, resp, err = s.UpdateContext(ctx, animeID, token, e)
+		return resp, err
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return libraryEntry, resp, nil
+}
+
+// RemoveWithSession is like RemoveContext but uses the token of the Session of
+// the Client. It returns ErrNoSession if the Client has no session.
+func (s *LibraryService) RemoveWithSession(ctx context.Context, animeID string) (bool, *http.Response, error) {
+	removed := false
+	resp, err := s.client.withSession(ctx, func(token string) (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		removed, resp, err = s.RemoveContext(ctx, animeID, token)
+		return resp, err
+	})
+	if err != nil {
+		return false, resp, err
+	}
+	return removed, resp, nil
+}
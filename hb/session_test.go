@@ -0,0 +1,96 @@
+package hb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestUserService_Login(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/users/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"username":"TestUser","password":"TestPass"}`+"\n")
+		fmt.Fprintf(w, `"token1234"`)
+	})
+
+	err := client.User.Login(context.Background(), Credentials{Username: "TestUser", Password: "TestPass"})
+	if err != nil {
+		t.Fatalf("User.Login returned error %v", err)
+	}
+	if got, want := client.Session.Token(), "token1234"; got != want {
+		t.Errorf("Session token is %v, want %v", got, want)
+	}
+}
+
+func TestLibraryService_UpdateWithSession(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Session = NewSession("valid_user_token", nil)
+
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"id":"log-horizon","auth_token":"valid_user_token","status":"completed"}`+"\n")
+		fmt.Fprintf(w, `{"id":7622,"status":"completed"}`)
+	})
+
+	libraryEntry, _, err := client.Library.UpdateWithSession(context.Background(), "log-horizon", &Entry{Status: StatusCompleted})
+	if err != nil {
+		t.Fatalf("Library.UpdateWithSession returned error %v", err)
+	}
+	if got, want := libraryEntry.ID, 7622; got != want {
+		t.Errorf("Library.UpdateWithSession libraryEntry.ID is %v, want %v", got, want)
+	}
+}
+
+func TestLibraryService_RemoveWithSession_reauthenticate(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Session = NewSession("expired_token", Credentials{Username: "TestUser", Password: "TestPass"})
+
+	logins := 0
+	mux.HandleFunc("/api/v1/users/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		fmt.Fprintf(w, `"new_token"`)
+	})
+	mux.HandleFunc("/api/v1/libraries/", func(w http.ResponseWriter, r *http.Request) {
+		testResourceParts(t, r, []string{"log-horizon", "remove"})
+		var e Entry
+		json.NewDecoder(r.Body).Decode(&e)
+		if e.AuthToken != "new_token" {
+			http.Error(w, `{"error":"Invalid authentication token"}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `true`)
+	})
+
+	removed, _, err := client.Library.RemoveWithSession(context.Background(), "log-horizon")
+	if err != nil {
+		t.Fatalf("Library.RemoveWithSession returned error %v", err)
+	}
+	if !removed {
+		t.Error("Library.RemoveWithSession returned false, want true")
+	}
+	if got, want := logins, 1; got != want {
+		t.Errorf("Session authenticated %v times, want %v", got, want)
+	}
+	if got, want := client.Session.Token(), "new_token"; got != want {
+		t.Errorf("Session token is %v, want %v", got, want)
+	}
+}
+
+func TestLibraryService_UpdateWithSession_noSession(t *testing.T) {
+	c := NewClient(nil)
+
+	_, resp, err := c.Library.UpdateWithSession(context.Background(), "log-horizon", nil)
+	if err != ErrNoSession {
+		t.Errorf("Library.UpdateWithSession returned error %v, want %v", err, ErrNoSession)
+	}
+	if resp != nil {
+		t.Error("Expected nil HTTP response when the client has no session.")
+	}
+}
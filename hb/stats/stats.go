@@ -0,0 +1,170 @@
+// Package stats computes statistics of a Hummingbird library, such as the
+// number of entries per status, the time spent watching anime and the
+// distribution of ratings, from the entries returned by UserService.Library.
+//
+// Stats is encoded as JSON with snake case keys, like the API, so that it can
+// be served as is.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Stats are the statistics of a library.
+type Stats struct {
+	// Entries is the number of library entries.
+	Entries int `json:"entries"`
+
+	// Statuses is the number of entries per status. All the statuses of the
+	// API are present, even when they have no entries.
+	Statuses map[hb.LibraryStatus]int `json:"statuses"`
+
+	// EpisodesWatched is the number of episodes watched, including the
+	// episodes of rewatches.
+	EpisodesWatched int `json:"episodes_watched"`
+
+	// MinutesWatched is the time spent watching the episodes in
+	// EpisodesWatched, in minutes. Entries whose anime has no episode length
+	// count as zero minutes.
+	MinutesWatched int `json:"minutes_watched"`
+
+	// Rewatches is the number of times that anime were rewatched.
+	Rewatches int `json:"rewatches"`
+
+	// Ratings are the statistics of the ratings of the entries.
+	Ratings Ratings `json:"ratings"`
+
+	// Genres and ShowTypes break the entries down by the genres and the show
+	// types of their anime, most entries first. An entry counts once for each
+	// genre of its anime.
+	//
+	// Note that the API doesn't include the genres of the anime in library
+	// entries. They have to be filled in, with AnimeService.Get for example,
+	// for Genres to have any counts.
+	Genres    []Count `json:"genres"`
+	ShowTypes []Count `json:"show_types"`
+
+	// CompletionRate and DropRate are the fractions, from 0 to 1, of the
+	// entries that were started, which are all entries except the ones
+	// planned to watch, that are completed or dropped.
+	CompletionRate float64 `json:"completion_rate"`
+	DropRate       float64 `json:"drop_rate"`
+}
+
+// Ratings are the statistics of the ratings of library entries. Simple
+// ratings are converted to advanced ratings as documented on hb.ParseRating.
+type Ratings struct {
+	// Count is the number of rated entries.
+	Count int `json:"count"`
+
+	// Mean is the mean rating, from 0 to 5, or 0 if there are no ratings.
+	Mean float64 `json:"mean"`
+
+	// Distribution is the number of entries per rating, such as "4.5".
+	Distribution map[string]int `json:"distribution"`
+}
+
+// Count is the number of entries and the minutes watched of one group of
+// entries, such as a genre.
+type Count struct {
+	Name           string `json:"name"`
+	Entries        int    `json:"entries"`
+	MinutesWatched int    `json:"minutes_watched"`
+}
+
+// WatchTime returns MinutesWatched as a duration.
+func (s *Stats) WatchTime() time.Duration {
+	return time.Duration(s.MinutesWatched) * time.Minute
+}
+
+// Compute computes the statistics of the library entries.
+//
+// The episodes of an entry are its episodes watched plus the episode count of
+// its anime for each time it was rewatched. Entries whose rating can't be
+// parsed are counted as not rated.
+func Compute(entries []hb.LibraryEntry) *Stats {
+	s := &Stats{
+		Entries: len(entries),
+		Statuses: map[hb.LibraryStatus]int{
+			hb.StatusCurrentlyWatching: 0,
+			hb.StatusPlanToWatch:       0,
+			hb.StatusCompleted:         0,
+			hb.StatusOnHold:            0,
+			hb.StatusDropped:           0,
+		},
+		Ratings: Ratings{Distribution: make(map[string]int)},
+	}
+	genres := make(counter)
+	showTypes := make(counter)
+	var ratingSum float64
+
+	for _, e := range entries {
+		s.Statuses[e.Status]++
+		s.Rewatches += e.RewatchedTimes
+
+		episodes, minutes := e.EpisodesWatched, 0
+		if a := e.Anime; a != nil {
+			episodes += e.RewatchedTimes * a.EpisodeCount
+			minutes = episodes * a.EpisodeLength
+			for _, g := range a.Genres {
+				genres.add(g.Name, minutes)
+			}
+			if a.ShowType != "" {
+				showTypes.add(a.ShowType, minutes)
+			}
+		}
+		s.EpisodesWatched += episodes
+		s.MinutesWatched += minutes
+
+		if e.Rating == nil {
+			continue
+		}
+		if r, err := e.Rating.Rating(); err == nil && r > 0 {
+			s.Ratings.Count++
+			s.Ratings.Distribution[r.String()]++
+			ratingSum += float64(r)
+		}
+	}
+
+	if s.Ratings.Count > 0 {
+		s.Ratings.Mean = ratingSum / float64(s.Ratings.Count)
+	}
+	if started := s.Entries - s.Statuses[hb.StatusPlanToWatch]; started > 0 {
+		s.CompletionRate = float64(s.Statuses[hb.StatusCompleted]) / float64(started)
+		s.DropRate = float64(s.Statuses[hb.StatusDropped]) / float64(started)
+	}
+	s.Genres = genres.counts()
+	s.ShowTypes = showTypes.counts()
+	return s
+}
+
+// counter accumulates the counts of groups of entries by name.
+type counter map[string]*Count
+
+func (c counter) add(name string, minutes int) {
+	count, ok := c[name]
+	if !ok {
+		count = &Count{Name: name}
+		c[name] = count
+	}
+	count.Entries++
+	count.MinutesWatched += minutes
+}
+
+// counts returns the counts sorted by entries, most first, and then by name.
+func (c counter) counts() []Count {
+	counts := make([]Count, 0, len(c))
+	for _, count := range c {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Entries != counts[j].Entries {
+			return counts[i].Entries > counts[j].Entries
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func testEntries() []hb.LibraryEntry {
+	series := &hb.Anime{
+		ID: 1, ShowType: "TV", EpisodeCount: 12, EpisodeLength: 24,
+		Genres: []hb.Genre{{Name: "Action"}, {Name: "Drama"}},
+	}
+	movie := &hb.Anime{ID: 2, ShowType: "Movie", EpisodeCount: 1, EpisodeLength: 100, Genres: []hb.Genre{{Name: "Drama"}}}
+	long := &hb.Anime{ID: 3, ShowType: "TV", EpisodeCount: 24, EpisodeLength: 24}
+	return []hb.LibraryEntry{
+		{
+			Anime: series, Status: hb.StatusCompleted, EpisodesWatched: 12, RewatchedTimes: 1,
+			Rating: &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: "4.5"},
+		},
+		{
+			Anime: movie, Status: hb.StatusCompleted, EpisodesWatched: 1,
+			Rating: &hb.LibraryEntryRating{Type: hb.RatingTypeSimple, Value: hb.RatingPositive},
+		},
+		{
+			Anime: long, Status: hb.StatusDropped, EpisodesWatched: 3,
+			Rating: &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: "2.0"},
+		},
+		{Status: hb.StatusPlanToWatch},
+		{Status: hb.StatusCurrentlyWatching, EpisodesWatched: 5},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	s := Compute(testEntries())
+
+	wantStatuses := map[hb.LibraryStatus]int{
+		hb.StatusCurrentlyWatching: 1,
+		hb.StatusPlanToWatch:       1,
+		hb.StatusCompleted:         2,
+		hb.StatusOnHold:            0,
+		hb.StatusDropped:           1,
+	}
+	if s.Entries != 5 {
+		t.Errorf("Entries are %v, want 5", s.Entries)
+	}
+	if !reflect.DeepEqual(s.Statuses, wantStatuses) {
+		t.Errorf("Statuses are %v, want %v", s.Statuses, wantStatuses)
+	}
+	if s.EpisodesWatched != 33 || s.MinutesWatched != 748 || s.Rewatches != 1 {
+		t.Errorf("Watched %v episodes in %v minutes with %v rewatches, want 33, 748, 1",
+			s.EpisodesWatched, s.MinutesWatched, s.Rewatches)
+	}
+	if got, want := s.WatchTime(), 748*time.Minute; got != want {
+		t.Errorf("WatchTime is %v, want %v", got, want)
+	}
+
+	wantDistribution := map[string]int{"4.5": 2, "2": 1}
+	if s.Ratings.Count != 3 || math.Abs(s.Ratings.Mean-11.0/3) > 1e-9 {
+		t.Errorf("Ratings are %v with mean %v, want 3 with mean %v", s.Ratings.Count, s.Ratings.Mean, 11.0/3)
+	}
+	if !reflect.DeepEqual(s.Ratings.Distribution, wantDistribution) {
+		t.Errorf("Rating distribution is %v, want %v", s.Ratings.Distribution, wantDistribution)
+	}
+
+	wantGenres := []Count{{"Drama", 2, 676}, {"Action", 1, 576}}
+	if !reflect.DeepEqual(s.Genres, wantGenres) {
+		t.Errorf("Genres are %+v, want %+v", s.Genres, wantGenres)
+	}
+	wantShowTypes := []Count{{"TV", 2, 648}, {"Movie", 1, 100}}
+	if !reflect.DeepEqual(s.ShowTypes, wantShowTypes) {
+		t.Errorf("ShowTypes are %+v, want %+v", s.ShowTypes, wantShowTypes)
+	}
+
+	if s.CompletionRate != 0.5 || s.DropRate != 0.25 {
+		t.Errorf("Completion and drop rates are %v and %v, want 0.5 and 0.25", s.CompletionRate, s.DropRate)
+	}
+}
+
+func TestCompute_empty(t *testing.T) {
+	s := Compute(nil)
+	if s.Entries != 0 || s.Ratings.Mean != 0 || s.CompletionRate != 0 || s.DropRate != 0 {
+		t.Errorf("Compute(nil) is %+v, want zero stats", s)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("Marshal returned error %v", err)
+	}
+}
+
+func TestStats_JSON(t *testing.T) {
+	b, err := json.Marshal(Compute(testEntries()))
+	if err != nil {
+		t.Fatalf("Marshal returned error %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	for _, key := range []string{
+		"entries", "statuses", "episodes_watched", "minutes_watched", "rewatches",
+		"ratings", "genres", "show_types", "completion_rate", "drop_rate",
+	} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("JSON %s has no %q", b, key)
+		}
+	}
+	if got, want := got["statuses"].(map[string]interface{})["on-hold"], 0.0; got != want {
+		t.Errorf("JSON on-hold status is %v, want %v", got, want)
+	}
+}
+
+func TestStats_JSON_unknownStatus(t *testing.T) {
+	// The API may return statuses that hb doesn't know of, which are kept
+	// as they are and must not make the stats fail to marshal.
+	entries := append(testEntries(), hb.LibraryEntry{Status: "rewatching"})
+	b, err := json.Marshal(Compute(entries))
+	if err != nil {
+		t.Fatalf("Marshal returned error %v", err)
+	}
+	var got struct {
+		Statuses map[string]int `json:"statuses"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+	if got, want := got.Statuses["rewatching"], 1; got != want {
+		t.Errorf("JSON rewatching status is %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package hb
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// StoryType is the type of a Story. Types that are not known are kept as
+// the API returns them.
+type StoryType string
+
+// Story types.
+const (
+	// StoryMedia is the story of the activity of a user on an anime or a
+	// manga. Its Media is the anime, or its Manga the manga, and its
+	// substories are the activities.
+	StoryMedia StoryType = "media_story"
+
+	// StoryComment is a comment posted on the feed of a user. Its Poster
+	// is the user who posted it and its substory holds the comment.
+	StoryComment StoryType = "comment"
+
+	// StoryFollowed is the story of a user following other users. Its
+	// substories are the users followed.
+	StoryFollowed StoryType = "followed"
+)
+
+// Known reports whether t is one of the story types above.
+func (t StoryType) Known() bool {
+	switch t {
+	case StoryMedia, StoryComment, StoryFollowed:
+		return true
+	}
+	return false
+}
+
+// SubstoryType is the type of a Substory. Types that are not known are kept
+// as the API returns them.
+type SubstoryType string
+
+// Substory types.
+const (
+	// SubstoryWatchedEpisode is an episode watched. EpisodeNumber holds the
+	// episode number, see AsWatchedEpisode.
+	SubstoryWatchedEpisode SubstoryType = "watched_episode"
+
+	// SubstoryStatusUpdate is a change of the status of a library entry.
+	// NewStatus holds the new status, see AsStatusUpdate.
+	SubstoryStatusUpdate SubstoryType = "watchlist_status_update"
+
+	// SubstoryFollowed is a user followed. FollowedUser holds the user, see
+	// AsFollowed.
+	SubstoryFollowed SubstoryType = "followed"
+
+	// SubstoryComment is a comment. Comment holds the comment, see
+	// AsComment.
+	SubstoryComment SubstoryType = "comment"
+)
+
+// Known reports whether t is one of the substory types above.
+func (t SubstoryType) Known() bool {
+	switch t {
+	case SubstoryWatchedEpisode, SubstoryStatusUpdate, SubstoryFollowed, SubstoryComment:
+		return true
+	}
+	return false
+}
+
+// AsMediaStory returns the anime of a media story. It returns false if the
+// story is of another type or has no anime.
+func (s *Story) AsMediaStory() (*Anime, bool) {
+	if s.StoryType != StoryMedia || s.Media == nil {
+		return nil, false
+	}
+	return s.Media, true
+}
+
+// AsMangaStory returns the manga of a media story. It returns false if the
+// story is of another type or has no manga.
+func (s *Story) AsMangaStory() (*Manga, bool) {
+	if s.StoryType != StoryMedia || s.Manga == nil {
+		return nil, false
+	}
+	return s.Manga, true
+}
+
+// UnmarshalJSON decodes the media of the story into Media or, for manga,
+// Manga. The media is a manga if the story has a "media_type" of
+// MediaTypeManga or, without one, if the media has the attributes of a manga.
+func (s *Story) UnmarshalJSON(data []byte) error {
+	type story Story
+	aux := struct {
+		*story
+		Media     json.RawMessage `json:"media,omitempty"`
+		MediaType string          `json:"media_type,omitempty"`
+	}{story: (*story)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	s.Media, s.Manga, err = decodeMedia(aux.MediaType, aux.Media)
+	return err
+}
+
+// MarshalJSON encodes the Manga of the story, if it has one, as its media.
+func (s Story) MarshalJSON() ([]byte, error) {
+	type story Story
+	aux := struct {
+		story
+		Media     interface{} `json:"media,omitempty"`
+		MediaType string      `json:"media_type,omitempty"`
+	}{story: story(s)}
+	switch {
+	case s.Manga != nil:
+		aux.Media, aux.MediaType = s.Manga, MediaTypeManga
+	case s.Media != nil:
+		aux.Media = s.Media
+	}
+	return json.Marshal(aux)
+}
+
+// AsWatchedEpisode returns the number of the episode watched. It returns
+// false if the substory is of another type or its episode number is not a
+// number.
+func (s *Substory) AsWatchedEpisode() (int, bool) {
+	if s.SubstoryType != SubstoryWatchedEpisode {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s.EpisodeNumber)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// AsStatusUpdate returns the new status of a status update. Statuses in any
+// of the forms accepted by ParseLibraryStatus, such as "currently_watching",
+// are returned in their API form and other statuses as they are. It returns
+// false if the substory is of another type.
+func (s *Substory) AsStatusUpdate() (LibraryStatus, bool) {
+	if s.SubstoryType != SubstoryStatusUpdate {
+		return "", false
+	}
+	status, err := ParseLibraryStatus(s.NewStatus)
+	if err != nil {
+		return LibraryStatus(s.NewStatus), true
+	}
+	return status, true
+}
+
+// AsMangaStatusUpdate is like AsStatusUpdate but for the status updates of
+// manga stories. Statuses in any of the forms accepted by
+// ParseMangaLibraryStatus are returned in their API form and other statuses
+// as they are.
+func (s *Substory) AsMangaStatusUpdate() (MangaLibraryStatus, bool) {
+	if s.SubstoryType != SubstoryStatusUpdate {
+		return "", false
+	}
+	status, err := ParseMangaLibraryStatus(s.NewStatus)
+	if err != nil {
+		return MangaLibraryStatus(s.NewStatus), true
+	}
+	return status, true
+}
+
+// AsFollowed returns the user followed. It returns false if the substory is
+// of another type or has no user.
+func (s *Substory) AsFollowed() (*UserMini, bool) {
+	if s.SubstoryType != SubstoryFollowed || s.FollowedUser == nil {
+		return nil, false
+	}
+	return s.FollowedUser, true
+}
+
+// AsComment returns the comment of a comment. It returns false if the
+// substory is of another type.
+func (s *Substory) AsComment() (string, bool) {
+	if s.SubstoryType != SubstoryComment {
+		return "", false
+	}
+	return s.Comment, true
+}
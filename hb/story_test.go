@@ -0,0 +1,133 @@
+package hb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSubstory_As(t *testing.T) {
+	var subs []Substory
+	in := `[
+		{"id":1,"substory_type":"watched_episode","episode_number":"12"},
+		{"id":2,"substory_type":"watchlist_status_update","new_status":"plan_to_watch"},
+		{"id":3,"substory_type":"followed","followed_user":{"name":"cybrox"}},
+		{"id":4,"substory_type":"comment","comment":"Hi!"},
+		{"id":5,"substory_type":"liked_post"}
+	]`
+	if err := json.Unmarshal([]byte(in), &subs); err != nil {
+		t.Fatalf("Unmarshal returned error %v", err)
+	}
+
+	if n, ok := subs[0].AsWatchedEpisode(); !ok || n != 12 {
+		t.Errorf("AsWatchedEpisode is %v, %v, want 12, true", n, ok)
+	}
+	if status, ok := subs[1].AsStatusUpdate(); !ok || status != StatusPlanToWatch {
+		t.Errorf("AsStatusUpdate is %v, %v, want %v, true", status, ok, StatusPlanToWatch)
+	}
+	if u, ok := subs[2].AsFollowed(); !ok || u.Name != "cybrox" {
+		t.Errorf("AsFollowed is %v, %v, want cybrox, true", u, ok)
+	}
+	if c, ok := subs[3].AsComment(); !ok || c != "Hi!" {
+		t.Errorf("AsComment is %q, %v, want %q, true", c, ok, "Hi!")
+	}
+
+	// Accessors of other types and unknown types return false.
+	if _, ok := subs[3].AsWatchedEpisode(); ok {
+		t.Error("AsWatchedEpisode of a comment returned true")
+	}
+	if _, ok := subs[0].AsComment(); ok {
+		t.Error("AsComment of a watched episode returned true")
+	}
+	unknown := subs[4]
+	if unknown.SubstoryType != "liked_post" || unknown.SubstoryType.Known() {
+		t.Errorf("Unknown substory type is %q, known %v", unknown.SubstoryType, unknown.SubstoryType.Known())
+	}
+	for _, ok := range []bool{
+		func() bool { _, ok := unknown.AsWatchedEpisode(); return ok }(),
+		func() bool { _, ok := unknown.AsStatusUpdate(); return ok }(),
+		func() bool { _, ok := unknown.AsFollowed(); return ok }(),
+		func() bool { _, ok := unknown.AsComment(); return ok }(),
+	} {
+		if ok {
+			t.Errorf("Accessor of unknown substory %+v returned true", unknown)
+		}
+	}
+
+	bad := Substory{SubstoryType: SubstoryWatchedEpisode, EpisodeNumber: "twelve"}
+	if _, ok := bad.AsWatchedEpisode(); ok {
+		t.Error("AsWatchedEpisode of a non numeric episode returned true")
+	}
+}
+
+func TestStory_AsMediaStory(t *testing.T) {
+	s := Story{StoryType: StoryMedia, Media: &Anime{ID: 7622}}
+	if a, ok := s.AsMediaStory(); !ok || a.ID != 7622 {
+		t.Errorf("AsMediaStory is %v, %v, want anime 7622, true", a, ok)
+	}
+	s = Story{StoryType: StoryComment}
+	if _, ok := s.AsMediaStory(); ok {
+		t.Error("AsMediaStory of a comment returned true")
+	}
+	if !StoryFollowed.Known() || StoryType("review").Known() {
+		t.Error("Known returned wrong results")
+	}
+}
+
+func TestStory_JSON(t *testing.T) {
+	tests := []struct {
+		data      string
+		wantAnime *Anime
+		wantManga *Manga
+	}{
+		{`{"id":1,"story_type":"media_story","media":{"id":1,"slug":"cowboy-bebop"}}`, &Anime{ID: 1, Slug: "cowboy-bebop"}, nil},
+		{`{"id":2,"story_type":"media_story","media":{"id":14,"slug":"berserk"},"media_type":"Manga"}`, nil, &Manga{ID: 14, Slug: "berserk"}},
+		{`{"id":3,"story_type":"media_story","media":{"id":14,"slug":"berserk","chapter_count":364}}`, nil, &Manga{ID: 14, Slug: "berserk", ChapterCount: 364}},
+		{`{"id":4,"story_type":"comment","media":null}`, nil, nil},
+	}
+	for _, tt := range tests {
+		var s Story
+		if err := json.Unmarshal([]byte(tt.data), &s); err != nil {
+			t.Fatalf("Unmarshal Story %s returned error %v", tt.data, err)
+		}
+		if !reflect.DeepEqual(s.Media, tt.wantAnime) || !reflect.DeepEqual(s.Manga, tt.wantManga) {
+			t.Errorf("Unmarshal Story %s media is %+v, %+v, want %+v, %+v", tt.data, s.Media, s.Manga, tt.wantAnime, tt.wantManga)
+		}
+
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("Marshal Story returned error %v", err)
+		}
+		var again Story
+		if err := json.Unmarshal(b, &again); err != nil {
+			t.Fatalf("Unmarshal marshaled Story %s returned error %v", b, err)
+		}
+		if !reflect.DeepEqual(again, s) {
+			t.Errorf("Story %s does not round trip: %+v, want %+v", b, again, s)
+		}
+	}
+}
+
+func TestStory_AsMangaStory(t *testing.T) {
+	manga := &Manga{Slug: "berserk"}
+	s := &Story{StoryType: StoryMedia, Manga: manga}
+
+	if got, ok := s.AsMangaStory(); !ok || got != manga {
+		t.Errorf("AsMangaStory is %v, %v, want %v, true", got, ok, manga)
+	}
+	if _, ok := s.AsMediaStory(); ok {
+		t.Errorf("AsMediaStory of a manga story returned true")
+	}
+}
+
+func TestSubstory_AsMangaStatusUpdate(t *testing.T) {
+	sub := &Substory{SubstoryType: SubstoryStatusUpdate, NewStatus: "currently_reading"}
+	if got, ok := sub.AsMangaStatusUpdate(); !ok || got != StatusCurrentlyReading {
+		t.Errorf("AsMangaStatusUpdate is %v, %v, want %v, true", got, ok, StatusCurrentlyReading)
+	}
+
+	sub = &Substory{SubstoryType: SubstoryWatchedEpisode, EpisodeNumber: "3"}
+	if _, ok := sub.AsMangaStatusUpdate(); ok {
+		t.Error("AsMangaStatusUpdate of a watched episode returned true")
+	}
+}
@@ -1,6 +1,8 @@
 package hb
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -15,7 +17,7 @@ type User struct {
 	WaifuCharID             string     `json:"waifu_char_id,omitempty"`
 	Location                string     `json:"location,omitempty"`
 	Website                 string     `json:"website,omitempty"`
-	Avatar                  string     `json:"website,omitempty"`
+	Avatar                  string     `json:"avatar,omitempty"`
 	CoverImage              string     `json:"cover_image,omitempty"`
 	About                   string     `json:"about,omitempty"`
 	Bio                     string     `json:"bio,omitempty"`
@@ -23,7 +25,7 @@ type User struct {
 	LifeSpentOnAnime        int        `json:"life_spent_on_anime,omitempty"`
 	ShowAdultContent        bool       `json:"show_adult_content,omitempty"`
 	TitleLanguagePreference string     `json:"title_language_preference,omitempty"`
-	LastLibraryUpdate       *time.Time `json:"last_library_update,omitempty"`
+	LastLibraryUpdate       *Date      `json:"last_library_update,omitempty"`
 	Online                  bool       `json:"online,omitempty"`
 	Following               bool       `json:"following,omitempty"`
 	Favorites               []Favorite `json:"favorites,omitempty"`
@@ -38,7 +40,8 @@ type UserMini struct {
 	NB          bool   `json:"nb,omitempty"`
 }
 
-// Favorite represents a favorite item of a Hummingbird user.
+// Favorite represents a favorite item of a Hummingbird user. ItemType is
+// either MediaTypeAnime or MediaTypeManga.
 type Favorite struct {
 	ID        int        `json:"id,omitempty"`
 	UserID    int        `json:"user_id,omitempty"`
@@ -47,6 +50,43 @@ type Favorite struct {
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 	FavRank   int        `json:"fav_rank,omitempty"`
+
+	// Anime or Manga is the favorite item, according to ItemType, when the
+	// API includes the item in the "item" field of the favorite.
+	Anime *Anime `json:"-"`
+	Manga *Manga `json:"-"`
+}
+
+// UnmarshalJSON decodes the item of the favorite, if there is one, into
+// Anime or Manga.
+func (f *Favorite) UnmarshalJSON(data []byte) error {
+	type favorite Favorite
+	aux := struct {
+		*favorite
+		Item json.RawMessage `json:"item,omitempty"`
+	}{favorite: (*favorite)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	f.Anime, f.Manga, err = decodeMedia(f.ItemType, aux.Item)
+	return err
+}
+
+// MarshalJSON encodes the Anime or Manga of the favorite as its item.
+func (f Favorite) MarshalJSON() ([]byte, error) {
+	type favorite Favorite
+	aux := struct {
+		favorite
+		Item interface{} `json:"item,omitempty"`
+	}{favorite: favorite(f)}
+	switch {
+	case f.Manga != nil:
+		aux.Item = f.Manga
+	case f.Anime != nil:
+		aux.Item = f.Anime
+	}
+	return json.Marshal(aux)
 }
 
 // UserService handles communication with the user methods of
@@ -68,6 +108,11 @@ type auth struct {
 // token can be used in other methods that require authentication. From
 // username and email only one is needed.
 func (s *UserService) Authenticate(username, email, password string) (string, *http.Response, error) {
+	return s.AuthenticateContext(context.Background(), username, email, password)
+}
+
+// AuthenticateContext is like Authenticate but sends the request bound to ctx.
+func (s *UserService) AuthenticateContext(ctx context.Context, username, email, password string) (string, *http.Response, error) {
 	if username == "" && email == "" {
 		return "", nil, fmt.Errorf("hb: username or email must be provided")
 	}
@@ -82,7 +127,7 @@ func (s *UserService) Authenticate(username, email, password string) (string, *h
 	}
 
 	var token string
-	resp, err := s.client.Do(req, &token)
+	resp, err := s.client.DoContext(withMethodName(ctx, "User.Authenticate"), req, &token)
 	if err != nil {
 		return "", resp, err
 	}
@@ -94,6 +139,11 @@ func (s *UserService) Authenticate(username, email, password string) (string, *h
 //
 // Does not require authentication.
 func (s *UserService) Get(username string) (*User, *http.Response, error) {
+	return s.GetContext(context.Background(), username)
+}
+
+// GetContext is like Get but sends the request bound to ctx.
+func (s *UserService) GetContext(ctx context.Context, username string) (*User, *http.Response, error) {
 	urlStr := fmt.Sprintf("api/v1/users/%s", username)
 
 	req, err := s.client.NewRequest("GET", urlStr, nil)
@@ -102,7 +152,7 @@ func (s *UserService) Get(username string) (*User, *http.Response, error) {
 	}
 
 	user := new(User)
-	resp, err := s.client.Do(req, user)
+	resp, err := s.client.DoContext(withMethodName(ctx, "User.Get"), req, user)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -112,31 +162,37 @@ func (s *UserService) Get(username string) (*User, *http.Response, error) {
 // Story represents a Hummingbird Story object such as a user's activity feed.
 type Story struct {
 	ID              int        `json:"id,omitempty"`
-	StoryType       string     `json:"story_type,omitempty"`
+	StoryType       StoryType  `json:"story_type,omitempty"`
 	User            *UserMini  `json:"user,omitempty"`
 	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
 	SelfPost        bool       `json:"self_post,omitempty"`
 	Poster          *UserMini  `json:"poster,omitempty"`
 	Media           *Anime     `json:"media,omitempty"`
+	Manga           *Manga     `json:"-"` // Set instead of Media for manga stories.
 	SubstoriesCount int        `json:"substories_count,omitempty"`
 	Substories      []Substory `json:"substories,omitempty"`
 }
 
 // Substory represents a Hummingbird Substory object.
 type Substory struct {
-	ID            int        `json:"id,omitempty"`
-	SubstoryType  string     `json:"substory_type,omitempty"`
-	CreatedAt     *time.Time `json:"created_at,omitempty"`
-	Comment       string     `json:"comment,omitempty"`
-	EpisodeNumber string     `json:"episode_number,omitempty"`
-	FollowedUser  *UserMini  `json:"followed_user,omitempty"`
-	NewStatus     string     `json:"new_status,omitempty"`
+	ID            int          `json:"id,omitempty"`
+	SubstoryType  SubstoryType `json:"substory_type,omitempty"`
+	CreatedAt     *time.Time   `json:"created_at,omitempty"`
+	Comment       string       `json:"comment,omitempty"`
+	EpisodeNumber string       `json:"episode_number,omitempty"`
+	FollowedUser  *UserMini    `json:"followed_user,omitempty"`
+	NewStatus     string       `json:"new_status,omitempty"`
 }
 
 // Feed returns a user's activity feed.
 //
 // Does not require authentication.
 func (s *UserService) Feed(username string) ([]Story, *http.Response, error) {
+	return s.FeedContext(context.Background(), username)
+}
+
+// FeedContext is like Feed but sends the request bound to ctx.
+func (s *UserService) FeedContext(ctx context.Context, username string) ([]Story, *http.Response, error) {
 	urlStr := fmt.Sprintf("api/v1/users/%s/feed", username)
 
 	req, err := s.client.NewRequest("GET", urlStr, nil)
@@ -145,7 +201,7 @@ func (s *UserService) Feed(username string) ([]Story, *http.Response, error) {
 	}
 
 	var stories []Story
-	resp, err := s.client.Do(req, &stories)
+	resp, err := s.client.DoContext(withMethodName(ctx, "User.Feed"), req, &stories)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -157,6 +213,11 @@ func (s *UserService) Feed(username string) ([]Story, *http.Response, error) {
 //
 // Does not require authentication.
 func (s *UserService) FavoriteAnime(username string) ([]Anime, *http.Response, error) {
+	return s.FavoriteAnimeContext(context.Background(), username)
+}
+
+// FavoriteAnimeContext is like FavoriteAnime but sends the request bound to ctx.
+func (s *UserService) FavoriteAnimeContext(ctx context.Context, username string) ([]Anime, *http.Response, error) {
 	urlStr := fmt.Sprintf("api/v1/users/%s/favorite_anime", username)
 
 	req, err := s.client.NewRequest("GET", urlStr, nil)
@@ -165,7 +226,7 @@ func (s *UserService) FavoriteAnime(username string) ([]Anime, *http.Response, e
 	}
 
 	var anime []Anime
-	resp, err := s.client.Do(req, &anime)
+	resp, err := s.client.DoContext(withMethodName(ctx, "User.FavoriteAnime"), req, &anime)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -184,8 +245,18 @@ func (s *UserService) FavoriteAnime(username string) ([]Anime, *http.Response, e
 //   hb.StatusOnHold
 //   hb.StatusDropped
 //
-// If omitted, results will include all statuses.
-func (s *UserService) Library(username, status string) ([]LibraryEntry, *http.Response, error) {
+// If omitted, results will include all statuses. Any other status is rejected
+// without sending a request.
+func (s *UserService) Library(username string, status LibraryStatus) ([]LibraryEntry, *http.Response, error) {
+	return s.LibraryContext(context.Background(), username, status)
+}
+
+// LibraryContext is like Library but sends the request bound to ctx.
+func (s *UserService) LibraryContext(ctx context.Context, username string, status LibraryStatus) ([]LibraryEntry, *http.Response, error) {
+	if err := status.validate(); err != nil {
+		return nil, nil, err
+	}
+
 	urlStr := fmt.Sprintf("api/v1/users/%s/library", username)
 
 	req, err := s.client.NewRequest("GET", urlStr, nil)
@@ -194,11 +265,11 @@ func (s *UserService) Library(username, status string) ([]LibraryEntry, *http.Re
 	}
 
 	v := req.URL.Query()
-	v.Set("status", status)
+	v.Set("status", string(status))
 	req.URL.RawQuery = v.Encode()
 
 	var entries []LibraryEntry
-	resp, err := s.client.Do(req, &entries)
+	resp, err := s.client.DoContext(withMethodName(ctx, "User.Library"), req, &entries)
 	if err != nil {
 		return nil, resp, err
 	}
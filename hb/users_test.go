@@ -1,6 +1,7 @@
 package hb
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -295,3 +296,37 @@ func TestUserService_Library_badUsername(t *testing.T) {
 		t.Error("Expected nil HTTP response when NewRequest fails.")
 	}
 }
+
+func TestUserService_LibraryContext_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/users/TestUser/library", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[]`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := client.User.LibraryContext(ctx, "TestUser", "")
+	if got, want := err, context.Canceled; got != want {
+		t.Errorf("User.LibraryContext returned error %v, want %v", got, want)
+	}
+}
+
+func TestUserService_Library_invalidStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/users/TestUser/library", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request sent with invalid status.")
+	})
+
+	_, resp, err := client.User.Library("TestUser", "currently-wathcing")
+	if err == nil {
+		t.Error("Expected invalid library status error.")
+	}
+	if resp != nil {
+		t.Error("Expected nil HTTP response when status is invalid.")
+	}
+}
@@ -0,0 +1,149 @@
+package v2
+
+import (
+	"strconv"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// animeStatuses maps the statuses of v2 anime to the statuses of v1.
+var animeStatuses = map[string]string{
+	"finished":   "Finished Airing",
+	"current":    "Currently Airing",
+	"upcoming":   "Not Yet Aired",
+	"unreleased": "Not Yet Aired",
+	"tba":        "Not Yet Aired",
+}
+
+// showTypes maps the show types of v2 anime to the show types of v1.
+var showTypes = map[string]string{
+	"TV":      "TV",
+	"movie":   "Movie",
+	"OVA":     "OVA",
+	"ONA":     "ONA",
+	"special": "Special",
+	"music":   "Music",
+}
+
+// libraryStatuses maps the statuses of v2 library entries to the statuses of
+// v1.
+var libraryStatuses = map[string]hb.LibraryStatus{
+	StatusCurrent:   hb.StatusCurrentlyWatching,
+	StatusPlanned:   hb.StatusPlanToWatch,
+	StatusCompleted: hb.StatusCompleted,
+	StatusOnHold:    hb.StatusOnHold,
+	StatusDropped:   hb.StatusDropped,
+}
+
+// LibraryStatus returns the v1 status that matches the v2 status of a
+// library entry, or the status unchanged if none does.
+func LibraryStatus(status string) hb.LibraryStatus {
+	if s, ok := libraryStatuses[status]; ok {
+		return s
+	}
+	return hb.LibraryStatus(status)
+}
+
+// ToAnime converts a v2 anime to the Anime of v1. Values that v1 doesn't
+// have are dropped. The community rating of v1 is out of 5, so an average
+// rating given as a percentage is scaled down.
+func ToAnime(a *Anime) *hb.Anime {
+	if a == nil {
+		return nil
+	}
+	id, _ := strconv.Atoi(a.ID)
+	anime := &hb.Anime{
+		ID:              id,
+		Slug:            a.Slug,
+		Status:          a.Status,
+		Title:           a.CanonicalTitle,
+		EpisodeCount:    a.EpisodeCount,
+		EpisodeLength:   a.EpisodeLength,
+		CoverImage:      a.PosterImage.Largest(),
+		Synopsis:        a.Synopsis,
+		ShowType:        a.ShowType,
+		StartedAiring:   a.StartDate,
+		FinishedAiring:  a.EndDate,
+		CommunityRating: a.AverageRating,
+		AgeRating:       a.AgeRating,
+	}
+	if a.Slug != "" {
+		anime.URL = "https://hummingbird.me/anime/" + a.Slug
+	}
+	if s, ok := animeStatuses[a.Status]; ok {
+		anime.Status = s
+	}
+	if t, ok := showTypes[a.ShowType]; ok {
+		anime.ShowType = t
+	}
+	if anime.CommunityRating > 5 {
+		anime.CommunityRating /= 20
+	}
+	for _, t := range []string{a.Titles["en"], a.Titles["en_jp"]} {
+		if t != "" && t != anime.Title {
+			anime.AlternateTitle = t
+			break
+		}
+	}
+	for _, g := range a.Genres {
+		anime.Genres = append(anime.Genres, hb.Genre{Name: g.Name})
+	}
+	return anime
+}
+
+// ToLibraryEntry converts a v2 library entry to the LibraryEntry of v1. The
+// Anime of the entry is set if the anime of e was included. The rating is
+// an advanced rating.
+func ToLibraryEntry(e *LibraryEntry) *hb.LibraryEntry {
+	if e == nil {
+		return nil
+	}
+	id, _ := strconv.Atoi(e.ID)
+	le := &hb.LibraryEntry{
+		ID:              id,
+		EpisodesWatched: e.Progress,
+		UpdatedAt:       e.UpdatedAt,
+		RewatchedTimes:  e.ReconsumeCount,
+		Notes:           e.Notes,
+		NotesPresent:    e.Notes != "",
+		Status:          LibraryStatus(e.Status),
+		Private:         e.Private,
+		Rewatching:      e.Reconsuming,
+		Anime:           ToAnime(e.Anime),
+	}
+	if e.ProgressedAt != nil {
+		le.LastWatched = &hb.Date{Time: *e.ProgressedAt}
+	}
+	var r hb.Rating
+	switch {
+	case e.RatingTwenty > 0:
+		// Ratings out of 20 are rounded to the half steps of v1.
+		r = hb.Rating(float64((e.RatingTwenty+1)/2) / 2)
+	case e.Rating != nil:
+		r = *e.Rating
+	}
+	if r > 0 {
+		le.Rating = &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: r.String()}
+	}
+	return le
+}
+
+// ToUser converts a v2 user to the User of v1.
+func ToUser(u *User) *hb.User {
+	if u == nil {
+		return nil
+	}
+	user := &hb.User{
+		Name:                    u.Name,
+		WaifuOrHusbando:         u.WaifuOrHusbando,
+		Location:                u.Location,
+		Website:                 u.Website,
+		Avatar:                  u.Avatar.Largest(),
+		CoverImage:              u.CoverImage.Largest(),
+		About:                   u.About,
+		Bio:                     u.Bio,
+		LifeSpentOnAnime:        u.LifeSpentOnAnime,
+		TitleLanguagePreference: u.TitleLanguagePreference,
+	}
+	return user
+}
@@ -0,0 +1,124 @@
+package v2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func TestToAnime(t *testing.T) {
+	start := &hb.Date{Time: time.Date(1998, 4, 3, 0, 0, 0, 0, time.UTC)}
+	a := &Anime{
+		ID:             "1",
+		Slug:           "cowboy-bebop",
+		CanonicalTitle: "Cowboy Bebop",
+		Titles:         map[string]string{"en": "Cowboy Bebop", "en_jp": "Kaubōi Bibappu"},
+		AverageRating:  88,
+		StartDate:      start,
+		PosterImage:    &Image{Large: "https://example.com/large.jpg"},
+		EpisodeCount:   26,
+		ShowType:       "movie",
+		Status:         "finished",
+		Genres:         []*Genre{{ID: "1", Name: "Action"}},
+	}
+
+	got := ToAnime(a)
+
+	want := &hb.Anime{
+		ID:              1,
+		Slug:            "cowboy-bebop",
+		URL:             "https://hummingbird.me/anime/cowboy-bebop",
+		Status:          "Finished Airing",
+		Title:           "Cowboy Bebop",
+		AlternateTitle:  "Kaubōi Bibappu",
+		EpisodeCount:    26,
+		CoverImage:      "https://example.com/large.jpg",
+		ShowType:        "Movie",
+		StartedAiring:   start,
+		CommunityRating: 4.4,
+		Genres:          []hb.Genre{{Name: "Action"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToAnime returned\n%+v, want\n%+v", got, want)
+	}
+	if ToAnime(nil) != nil {
+		t.Errorf("ToAnime(nil) is not nil")
+	}
+}
+
+func TestToLibraryEntry(t *testing.T) {
+	progressed := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in   *LibraryEntry
+		want *hb.LibraryEntry
+	}{
+		{
+			in: &LibraryEntry{
+				ID:             "100",
+				Status:         StatusOnHold,
+				Progress:       5,
+				ReconsumeCount: 1,
+				Notes:          "good",
+				Rating:         hb.NewRating(3.5),
+				ProgressedAt:   &progressed,
+				Anime:          &Anime{ID: "1", Slug: "cowboy-bebop"},
+			},
+			want: &hb.LibraryEntry{
+				ID:              100,
+				Status:          hb.StatusOnHold,
+				EpisodesWatched: 5,
+				RewatchedTimes:  1,
+				Notes:           "good",
+				NotesPresent:    true,
+				Rating:          &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: "3.5"},
+				LastWatched:     &hb.Date{Time: progressed},
+				Anime:           &hb.Anime{ID: 1, Slug: "cowboy-bebop", URL: "https://hummingbird.me/anime/cowboy-bebop"},
+			},
+		},
+		{
+			in:   &LibraryEntry{ID: "101", Status: StatusPlanned, RatingTwenty: 15},
+			want: &hb.LibraryEntry{ID: 101, Status: hb.StatusPlanToWatch, Rating: &hb.LibraryEntryRating{Type: hb.RatingTypeAdvanced, Value: "4"}},
+		},
+		{
+			in:   &LibraryEntry{ID: "102", Status: StatusCurrent},
+			want: &hb.LibraryEntry{ID: 102, Status: hb.StatusCurrentlyWatching},
+		},
+	}
+	for _, tt := range tests {
+		if got := ToLibraryEntry(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ToLibraryEntry(%+v) returned\n%+v, want\n%+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLibraryStatus(t *testing.T) {
+	tests := []struct {
+		in   string
+		want hb.LibraryStatus
+	}{
+		{StatusCurrent, hb.StatusCurrentlyWatching},
+		{StatusPlanned, hb.StatusPlanToWatch},
+		{StatusCompleted, hb.StatusCompleted},
+		{StatusOnHold, hb.StatusOnHold},
+		{StatusDropped, hb.StatusDropped},
+		{"unknown", hb.LibraryStatus("unknown")},
+	}
+	for _, tt := range tests {
+		if got := LibraryStatus(tt.in); got != tt.want {
+			t.Errorf("LibraryStatus(%q) is %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToUser(t *testing.T) {
+	u := &User{ID: "7", Name: "cybrox", Bio: "bio", LifeSpentOnAnime: 3200, Avatar: &Image{Medium: "https://example.com/a.png"}}
+
+	got := ToUser(u)
+
+	want := &hb.User{Name: "cybrox", Bio: "bio", LifeSpentOnAnime: 3200, Avatar: "https://example.com/a.png"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToUser returned %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Image holds the URLs of an image in different sizes. Not all sizes are
+// available for every image.
+type Image struct {
+	Tiny     string `json:"tiny,omitempty"`
+	Small    string `json:"small,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Large    string `json:"large,omitempty"`
+	Original string `json:"original,omitempty"`
+}
+
+// Largest returns the URL of the largest available size of the image.
+func (i *Image) Largest() string {
+	if i == nil {
+		return ""
+	}
+	for _, u := range []string{i.Original, i.Large, i.Medium, i.Small, i.Tiny} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// Genre is a genre resource.
+type Genre struct {
+	ID          string `json:"-"`
+	Name        string `json:"name,omitempty"`
+	Slug        string `json:"slug,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Anime is an anime resource.
+type Anime struct {
+	ID                string            `json:"-"`
+	Slug              string            `json:"slug,omitempty"`
+	Synopsis          string            `json:"synopsis,omitempty"`
+	Titles            map[string]string `json:"titles,omitempty"`
+	CanonicalTitle    string            `json:"canonicalTitle,omitempty"`
+	AbbreviatedTitles []string          `json:"abbreviatedTitles,omitempty"`
+	AverageRating     float64           `json:"averageRating,omitempty"`
+	StartDate         *hb.Date          `json:"startDate,omitempty"`
+	EndDate           *hb.Date          `json:"endDate,omitempty"`
+	PosterImage       *Image            `json:"posterImage,omitempty"`
+	CoverImage        *Image            `json:"coverImage,omitempty"`
+	EpisodeCount      int               `json:"episodeCount,omitempty"`
+	EpisodeLength     int               `json:"episodeLength,omitempty"`
+	ShowType          string            `json:"showType,omitempty"`
+	Status            string            `json:"status,omitempty"`
+	AgeRating         string            `json:"ageRating,omitempty"`
+	NSFW              bool              `json:"nsfw,omitempty"`
+
+	// Genres are the genres of the anime, which are only set if the
+	// "genres" relationship was included.
+	Genres []*Genre `json:"-"`
+}
+
+// AnimeService handles communication with the anime resources of the API.
+type AnimeService struct {
+	client *Client
+}
+
+// Get returns the anime with the given ID.
+func (s *AnimeService) Get(ctx context.Context, id string, opts *ListOptions) (*Anime, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("anime/"+url.PathEscape(id), opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	r, err := doc.Resource()
+	if err != nil || r == nil {
+		return nil, resp, err
+	}
+	a, err := decodeAnime(r, doc)
+	return a, resp, err
+}
+
+// List returns a page of anime.
+func (s *AnimeService) List(ctx context.Context, opts *ListOptions) ([]*Anime, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("anime", opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	rs, err := doc.Resources()
+	if err != nil {
+		return nil, resp, err
+	}
+	anime := make([]*Anime, len(rs))
+	for i := range rs {
+		if anime[i], err = decodeAnime(&rs[i], doc); err != nil {
+			return nil, resp, err
+		}
+	}
+	return anime, resp, nil
+}
+
+// Search returns a page of the anime that match text.
+func (s *AnimeService) Search(ctx context.Context, text string, opts *ListOptions) ([]*Anime, *Response, error) {
+	return s.List(ctx, withFilter(opts, "text", text))
+}
+
+// decodeAnime decodes the anime resource r of doc.
+func decodeAnime(r *Resource, doc *Document) (*Anime, error) {
+	if r.Type != "anime" {
+		return nil, fmt.Errorf("v2: resource %v %v is not anime", r.Type, r.ID)
+	}
+	a := &Anime{ID: r.ID}
+	if err := r.Decode(a); err != nil {
+		return nil, err
+	}
+	genres, err := decodeGenres(r, doc)
+	if err != nil {
+		return nil, err
+	}
+	a.Genres = genres
+	return a, nil
+}
+
+// decodeGenres decodes the included genres of the resource r of doc.
+func decodeGenres(r *Resource, doc *Document) ([]*Genre, error) {
+	var genres []*Genre
+	for _, id := range r.Related("genres") {
+		gr := doc.Find(id)
+		if gr == nil {
+			continue
+		}
+		g := &Genre{ID: gr.ID}
+		if err := gr.Decode(g); err != nil {
+			return nil, err
+		}
+		genres = append(genres, g)
+	}
+	return genres, nil
+}
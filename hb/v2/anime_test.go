@@ -0,0 +1,131 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+const cowboyBebop = `{
+	"data": {
+		"id": "1",
+		"type": "anime",
+		"attributes": {
+			"slug": "cowboy-bebop",
+			"canonicalTitle": "Cowboy Bebop",
+			"titles": {"en": "Cowboy Bebop", "en_jp": "Cowboy Bebop", "ja_jp": "カウボーイビバップ"},
+			"averageRating": 88.55,
+			"startDate": "1998-04-03",
+			"endDate": "1999-04-24",
+			"posterImage": {"small": "https://example.com/small.jpg", "original": "https://example.com/original.jpg"},
+			"episodeCount": 26,
+			"episodeLength": 25,
+			"showType": "TV",
+			"status": "finished",
+			"ageRating": "R"
+		},
+		"relationships": {
+			"genres": {"data": [{"id": "1", "type": "genres"}, {"id": "2", "type": "genres"}]}
+		}
+	},
+	"included": [
+		{"id": "1", "type": "genres", "attributes": {"name": "Action", "slug": "action"}},
+		{"id": "2", "type": "genres", "attributes": {"name": "Space", "slug": "space"}}
+	]
+}`
+
+func TestAnimeService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testQuery(t, r, url.Values{"include": {"genres"}})
+		fmt.Fprint(w, cowboyBebop)
+	})
+
+	got, _, err := client.Anime.Get(context.Background(), "1", &ListOptions{Include: []string{"genres"}})
+	if err != nil {
+		t.Fatalf("Anime.Get returned error %v", err)
+	}
+
+	want := &Anime{
+		ID:             "1",
+		Slug:           "cowboy-bebop",
+		CanonicalTitle: "Cowboy Bebop",
+		Titles:         map[string]string{"en": "Cowboy Bebop", "en_jp": "Cowboy Bebop", "ja_jp": "カウボーイビバップ"},
+		AverageRating:  88.55,
+		StartDate:      &hb.Date{Time: time.Date(1998, 4, 3, 0, 0, 0, 0, time.UTC)},
+		EndDate:        &hb.Date{Time: time.Date(1999, 4, 24, 0, 0, 0, 0, time.UTC)},
+		PosterImage:    &Image{Small: "https://example.com/small.jpg", Original: "https://example.com/original.jpg"},
+		EpisodeCount:   26,
+		EpisodeLength:  25,
+		ShowType:       "TV",
+		Status:         "finished",
+		AgeRating:      "R",
+		Genres: []*Genre{
+			{ID: "1", Name: "Action", Slug: "action"},
+			{ID: "2", Name: "Space", Slug: "space"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.Get returned\n%+v, want\n%+v", got, want)
+	}
+}
+
+func TestAnimeService_Search(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testQuery(t, r, url.Values{"filter[text]": {"bebop"}, "page[limit]": {"1"}})
+		fmt.Fprint(w, `{"data":[{"id":"1","type":"anime","attributes":{"slug":"cowboy-bebop"}}]}`)
+	})
+
+	got, _, err := client.Anime.Search(context.Background(), "bebop", &ListOptions{PageLimit: 1})
+	if err != nil {
+		t.Fatalf("Anime.Search returned error %v", err)
+	}
+
+	want := []*Anime{{ID: "1", Slug: "cowboy-bebop"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Anime.Search returned %+v, want %+v", got, want)
+	}
+}
+
+func TestAnimeService_Get_wrongType(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"id":"1","type":"manga"}}`)
+	})
+
+	if _, _, err := client.Anime.Get(context.Background(), "1", nil); err == nil {
+		t.Errorf("Anime.Get of a manga resource returned no error")
+	}
+}
+
+func TestImage_Largest(t *testing.T) {
+	tests := []struct {
+		in   *Image
+		want string
+	}{
+		{nil, ""},
+		{&Image{}, ""},
+		{&Image{Tiny: "t", Medium: "m"}, "m"},
+		{&Image{Large: "l", Original: "o"}, "o"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Largest(); got != tt.want {
+			t.Errorf("%+v.Largest() is %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
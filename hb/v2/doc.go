@@ -0,0 +1,76 @@
+/*
+Package v2 provides a client for accessing version 2 of the Hummingbird.me
+API, which follows the JSON:API specification (http://jsonapi.org).
+
+Construct a new client, then use one of the client's services to access the
+different resources of the API. For example, to list the library entries of a
+user along with their anime:
+
+	c := v2.NewClient(nil)
+
+	entries, _, err := c.LibraryEntries.List(ctx, &v2.ListOptions{
+		Filter:  map[string]string{"userId": "1"},
+		Include: []string{"anime"},
+	})
+	// handle err
+
+Lists are paginated. The Next field of the Response holds the link to the
+next page, which can be followed by setting the Link of the ListOptions:
+
+	opts := &v2.ListOptions{PageLimit: 20}
+	for {
+		anime, resp, err := c.Anime.List(ctx, opts)
+		// handle err and anime
+		if resp.Next == "" {
+			break
+		}
+		opts = &v2.ListOptions{Link: resp.Next}
+	}
+
+Library entries of anime and manga are listed, created, updated and deleted
+with the LibraryEntries service. For example, to add a manga to the library
+of a user and then update the chapters read:
+
+	e, _, err := c.LibraryEntries.Create(ctx, userID, v2.Identifier{ID: "3", Type: "manga"},
+		&v2.LibraryEntryAttributes{Status: hb.String(v2.StatusCurrent)})
+	// handle err
+
+	e, _, err = c.LibraryEntries.Update(ctx, e.ID, &v2.LibraryEntryAttributes{Progress: hb.Int(12)})
+
+The manga library of a user is listed with the "kind" filter:
+
+	opts := &v2.ListOptions{Filter: map[string]string{"kind": "manga"}, Include: []string{"manga"}}
+	entries, _, err := c.LibraryEntries.ListByUser(ctx, userID, opts)
+
+Sparse fieldsets limit the attributes returned for each type of resource:
+
+	opts := &v2.ListOptions{Fields: map[string][]string{"anime": {"slug", "canonicalTitle"}}}
+
+Requests are authenticated with OAuth2 tokens, which are obtained with a
+Config of the application. The password grant exchanges the credentials of a
+user for a token:
+
+	conf := &v2.Config{ClientID: id, ClientSecret: secret}
+	tok, err := conf.PasswordToken(ctx, "cybrox", password)
+	// handle err
+
+The TokenSource of the Client attaches the token to the requests and
+refreshes it when it expires. A TokenStore keeps the token between runs of
+the program and saves the refreshed tokens:
+
+	store := v2.NewFileTokenStore("token.json")
+	store.SaveToken(tok)
+
+	c := v2.NewClient(nil)
+	c.TokenSource = conf.TokenSource(nil, store)
+
+Applications that don't act on behalf of a user can use the client
+credentials grant with Config.ClientCredentialsTokenSource instead.
+
+The resources of the API can be converted to the types of package hb, used by
+version 1 of the API, with the adapter functions such as ToAnime.
+
+The generic decoder of JSON:API documents, Document, can be used for
+resources that the services don't cover.
+*/
+package v2
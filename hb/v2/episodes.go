@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Episode is an episode resource.
+type Episode struct {
+	ID             string            `json:"-"`
+	Titles         map[string]string `json:"titles,omitempty"`
+	CanonicalTitle string            `json:"canonicalTitle,omitempty"`
+	SeasonNumber   int               `json:"seasonNumber,omitempty"`
+	Number         int               `json:"number,omitempty"`
+	RelativeNumber int               `json:"relativeNumber,omitempty"`
+	Synopsis       string            `json:"synopsis,omitempty"`
+	Airdate        *hb.Date          `json:"airdate,omitempty"`
+	Length         int               `json:"length,omitempty"`
+	Thumbnail      *Image            `json:"thumbnail,omitempty"`
+}
+
+// EpisodeService handles communication with the episode resources of the
+// API.
+type EpisodeService struct {
+	client *Client
+}
+
+// Get returns the episode with the given ID.
+func (s *EpisodeService) Get(ctx context.Context, id string, opts *ListOptions) (*Episode, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("episodes/"+url.PathEscape(id), opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	r, err := doc.Resource()
+	if err != nil || r == nil {
+		return nil, resp, err
+	}
+	e, err := decodeEpisode(r)
+	return e, resp, err
+}
+
+// List returns a page of episodes.
+func (s *EpisodeService) List(ctx context.Context, opts *ListOptions) ([]*Episode, *Response, error) {
+	return s.list(ctx, resourceURL("episodes", opts))
+}
+
+// ListByAnime returns a page of the episodes of the anime with the given ID.
+func (s *EpisodeService) ListByAnime(ctx context.Context, animeID string, opts *ListOptions) ([]*Episode, *Response, error) {
+	return s.list(ctx, resourceURL("anime/"+url.PathEscape(animeID)+"/episodes", opts))
+}
+
+func (s *EpisodeService) list(ctx context.Context, urlStr string) ([]*Episode, *Response, error) {
+	doc, resp, err := s.client.get(ctx, urlStr)
+	if err != nil {
+		return nil, resp, err
+	}
+	rs, err := doc.Resources()
+	if err != nil {
+		return nil, resp, err
+	}
+	episodes := make([]*Episode, len(rs))
+	for i := range rs {
+		if episodes[i], err = decodeEpisode(&rs[i]); err != nil {
+			return nil, resp, err
+		}
+	}
+	return episodes, resp, nil
+}
+
+// decodeEpisode decodes the episode resource r.
+func decodeEpisode(r *Resource) (*Episode, error) {
+	if r.Type != "episodes" {
+		return nil, fmt.Errorf("v2: resource %v %v is not an episode", r.Type, r.ID)
+	}
+	e := &Episode{ID: r.ID}
+	if err := r.Decode(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,39 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func TestEpisodeService_ListByAnime(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime/1/episodes", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"data":[{"id":"10","type":"episodes","attributes":{"canonicalTitle":"Asteroid Blues","number":1,"seasonNumber":1,"airdate":"1998-10-24","length":25}}]}`)
+	})
+
+	got, _, err := client.Episodes.ListByAnime(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("Episodes.ListByAnime returned error %v", err)
+	}
+
+	want := []*Episode{{
+		ID:             "10",
+		CanonicalTitle: "Asteroid Blues",
+		Number:         1,
+		SeasonNumber:   1,
+		Airdate:        &hb.Date{Time: time.Date(1998, 10, 24, 0, 0, 0, 0, time.UTC)},
+		Length:         25,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Episodes.ListByAnime returned %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Document is a JSON:API document, the top level object of the responses of
+// the API.
+type Document struct {
+	// Data is the primary data of the document: a resource, an array of
+	// resources or null. Use Resource or Resources to decode it.
+	Data json.RawMessage `json:"data,omitempty"`
+
+	// Included are the resources related to the primary data that were
+	// requested with ListOptions.Include.
+	Included []Resource `json:"included,omitempty"`
+
+	Links  Links                  `json:"links,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+	Errors []ErrorObject          `json:"errors,omitempty"`
+}
+
+// Resource decodes the primary data of the document as a single resource. It
+// returns nil if the data is null.
+func (d *Document) Resource() (*Resource, error) {
+	if isNull(d.Data) {
+		return nil, nil
+	}
+	r := new(Resource)
+	if err := json.Unmarshal(d.Data, r); err != nil {
+		return nil, fmt.Errorf("v2: cannot decode resource: %v", err)
+	}
+	return r, nil
+}
+
+// Resources decodes the primary data of the document as an array of
+// resources.
+func (d *Document) Resources() ([]Resource, error) {
+	if isNull(d.Data) {
+		return nil, nil
+	}
+	var rs []Resource
+	if err := json.Unmarshal(d.Data, &rs); err != nil {
+		return nil, fmt.Errorf("v2: cannot decode resources: %v", err)
+	}
+	return rs, nil
+}
+
+// Find returns the included resource with the given type and ID, or nil if
+// it was not included.
+func (d *Document) Find(id Identifier) *Resource {
+	for i := range d.Included {
+		if d.Included[i].Type == id.Type && d.Included[i].ID == id.ID {
+			return &d.Included[i]
+		}
+	}
+	return nil
+}
+
+// Identifier identifies a resource by its type and ID.
+type Identifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Resource is a JSON:API resource object.
+type Resource struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+
+	// Attributes are the raw attributes of the resource, which are decoded
+	// with Decode.
+	Attributes    json.RawMessage         `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         Links                   `json:"links,omitempty"`
+	Meta          map[string]interface{}  `json:"meta,omitempty"`
+}
+
+// Identifier returns the identifier of the resource.
+func (r *Resource) Identifier() Identifier {
+	return Identifier{ID: r.ID, Type: r.Type}
+}
+
+// Decode decodes the attributes of the resource into v.
+func (r *Resource) Decode(v interface{}) error {
+	if isNull(r.Attributes) {
+		return nil
+	}
+	if err := json.Unmarshal(r.Attributes, v); err != nil {
+		return fmt.Errorf("v2: cannot decode attributes of %v %v: %v", r.Type, r.ID, err)
+	}
+	return nil
+}
+
+// Related returns the identifiers of the resources of a relationship, which
+// are empty if the relationship has no data.
+func (r *Resource) Related(name string) []Identifier {
+	rel, ok := r.Relationships[name]
+	if !ok {
+		return nil
+	}
+	return rel.Identifiers()
+}
+
+// Relationship is a JSON:API relationship object. Data holds a resource
+// identifier, an array of them or null, and is absent if the relationship was
+// not requested.
+type Relationship struct {
+	Data  json.RawMessage        `json:"data,omitempty"`
+	Links Links                  `json:"links,omitempty"`
+	Meta  map[string]interface{} `json:"meta,omitempty"`
+}
+
+// Identifiers returns the identifiers of the resources of the relationship,
+// whether it is a to-one or a to-many relationship.
+func (r Relationship) Identifiers() []Identifier {
+	data := bytes.TrimSpace(r.Data)
+	if isNull(data) {
+		return nil
+	}
+	if data[0] == '[' {
+		var ids []Identifier
+		json.Unmarshal(data, &ids)
+		return ids
+	}
+	var id Identifier
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil
+	}
+	return []Identifier{id}
+}
+
+// Links are JSON:API links by name, such as "self" or "next". Links given as
+// link objects are reduced to their href.
+type Links map[string]string
+
+// UnmarshalJSON decodes links that are either strings or link objects.
+func (l *Links) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	links := make(Links, len(raw))
+	for name, v := range raw {
+		if isNull(v) {
+			continue
+		}
+		var href string
+		if err := json.Unmarshal(v, &href); err != nil {
+			var obj struct {
+				Href string `json:"href"`
+			}
+			if err := json.Unmarshal(v, &obj); err != nil {
+				return fmt.Errorf("v2: cannot decode link %q: %v", name, err)
+			}
+			href = obj.Href
+		}
+		links[name] = href
+	}
+	*l = links
+	return nil
+}
+
+// requestDocument is the document of the requests that create or update a
+// resource.
+type requestDocument struct {
+	Data requestResource `json:"data"`
+}
+
+// requestResource is the resource of a requestDocument. Unlike Resource, its
+// ID is omitted when it is empty, as it is for new resources.
+type requestResource struct {
+	ID            string                         `json:"id,omitempty"`
+	Type          string                         `json:"type"`
+	Attributes    interface{}                    `json:"attributes,omitempty"`
+	Relationships map[string]requestRelationship `json:"relationships,omitempty"`
+}
+
+// requestRelationship is a to-one relationship of a requestResource.
+type requestRelationship struct {
+	Data Identifier `json:"data"`
+}
+
+// isNull reports whether the raw JSON value data is absent or null.
+func isNull(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) == 0 || bytes.Equal(data, []byte("null"))
+}
@@ -0,0 +1,92 @@
+package v2
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLinks_UnmarshalJSON(t *testing.T) {
+	var links Links
+	data := `{"self":"https://a","related":{"href":"https://b","meta":{}},"next":null}`
+
+	if err := json.Unmarshal([]byte(data), &links); err != nil {
+		t.Fatalf("Unmarshal Links returned error %v", err)
+	}
+
+	want := Links{"self": "https://a", "related": "https://b"}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("Links is %v, want %v", links, want)
+	}
+}
+
+func TestRelationship_Identifiers(t *testing.T) {
+	tests := []struct {
+		data string
+		want []Identifier
+	}{
+		{``, nil},
+		{`null`, nil},
+		{`[]`, []Identifier{}},
+		{`{"id":"1","type":"anime"}`, []Identifier{{ID: "1", Type: "anime"}}},
+		{`[{"id":"1","type":"genres"},{"id":"2","type":"genres"}]`, []Identifier{{ID: "1", Type: "genres"}, {ID: "2", Type: "genres"}}},
+	}
+	for _, tt := range tests {
+		r := Relationship{Data: json.RawMessage(tt.data)}
+		if got := r.Identifiers(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Relationship{%s}.Identifiers is %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDocument(t *testing.T) {
+	data := `{
+		"data": {
+			"id": "1",
+			"type": "anime",
+			"attributes": {"slug": "cowboy-bebop"},
+			"relationships": {
+				"genres": {"data": [{"id": "5", "type": "genres"}, {"id": "6", "type": "genres"}]},
+				"episodes": {"links": {"related": "https://example.com/anime/1/episodes"}}
+			}
+		},
+		"included": [{"id": "5", "type": "genres", "attributes": {"name": "Action"}}]
+	}`
+	var doc Document
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("Unmarshal Document returned error %v", err)
+	}
+
+	r, err := doc.Resource()
+	if err != nil {
+		t.Fatalf("Document.Resource returned error %v", err)
+	}
+	if got, want := r.Identifier(), (Identifier{ID: "1", Type: "anime"}); got != want {
+		t.Errorf("Resource.Identifier is %v, want %v", got, want)
+	}
+	var attrs struct{ Slug string }
+	if err := r.Decode(&attrs); err != nil {
+		t.Fatalf("Resource.Decode returned error %v", err)
+	}
+	if got, want := attrs.Slug, "cowboy-bebop"; got != want {
+		t.Errorf("Resource slug is %v, want %v", got, want)
+	}
+	if got := r.Related("episodes"); got != nil {
+		t.Errorf("Resource.Related episodes without data is %v, want nil", got)
+	}
+	if got, want := r.Relationships["episodes"].Links["related"], "https://example.com/anime/1/episodes"; got != want {
+		t.Errorf("Relationship related link is %v, want %v", got, want)
+	}
+
+	genres := r.Related("genres")
+	if g := doc.Find(genres[0]); g == nil || g.ID != "5" {
+		t.Errorf("Document.Find(%v) is %+v, want genre 5", genres[0], g)
+	}
+	if g := doc.Find(genres[1]); g != nil {
+		t.Errorf("Document.Find(%v) of resource not included is %+v, want nil", genres[1], g)
+	}
+
+	if _, err := doc.Resources(); err == nil {
+		t.Errorf("Document.Resources of a single resource returned no error")
+	}
+}
@@ -0,0 +1,194 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Library entry statuses.
+const (
+	StatusCurrent   = "current"
+	StatusPlanned   = "planned"
+	StatusCompleted = "completed"
+	StatusOnHold    = "on_hold"
+	StatusDropped   = "dropped"
+)
+
+// LibraryEntry is a library entry resource.
+type LibraryEntry struct {
+	ID             string     `json:"-"`
+	Status         string     `json:"status,omitempty"`
+	Progress       int        `json:"progress,omitempty"`
+	Reconsuming    bool       `json:"reconsuming,omitempty"`
+	ReconsumeCount int        `json:"reconsumeCount,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	Private        bool       `json:"private,omitempty"`
+	Rating         *hb.Rating `json:"rating,omitempty"`
+
+	// RatingTwenty is the rating on a scale of 2 to 20, which newer
+	// versions of the API return instead of Rating.
+	RatingTwenty int `json:"ratingTwenty,omitempty"`
+
+	ProgressedAt *time.Time `json:"progressedAt,omitempty"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+
+	// Anime, Manga and User are the anime, the manga and the user of the
+	// entry, which are only set if the "anime", "manga" or "media" and the
+	// "user" relationships were included.
+	Anime *Anime `json:"-"`
+	Manga *Manga `json:"-"`
+	User  *User  `json:"-"`
+}
+
+// LibraryEntryAttributes are the attributes of a library entry that Create
+// and Update send. Nil fields are not sent, so Update leaves them unchanged,
+// while non-nil zero values are sent. Use hb.Int, hb.Bool, hb.String and
+// hb.NewRating to set them.
+type LibraryEntryAttributes struct {
+	Status         *string    `json:"status,omitempty"`
+	Progress       *int       `json:"progress,omitempty"`
+	Reconsuming    *bool      `json:"reconsuming,omitempty"`
+	ReconsumeCount *int       `json:"reconsumeCount,omitempty"`
+	Notes          *string    `json:"notes,omitempty"`
+	Private        *bool      `json:"private,omitempty"`
+	Rating         *hb.Rating `json:"rating,omitempty"`
+}
+
+// LibraryEntryService handles communication with the library entry resources
+// of the API.
+type LibraryEntryService struct {
+	client *Client
+}
+
+// Get returns the library entry with the given ID.
+func (s *LibraryEntryService) Get(ctx context.Context, id string, opts *ListOptions) (*LibraryEntry, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("library-entries/"+url.PathEscape(id), opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	r, err := doc.Resource()
+	if err != nil || r == nil {
+		return nil, resp, err
+	}
+	e, err := decodeLibraryEntry(r, doc)
+	return e, resp, err
+}
+
+// List returns a page of library entries.
+func (s *LibraryEntryService) List(ctx context.Context, opts *ListOptions) ([]*LibraryEntry, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("library-entries", opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	rs, err := doc.Resources()
+	if err != nil {
+		return nil, resp, err
+	}
+	entries := make([]*LibraryEntry, len(rs))
+	for i := range rs {
+		if entries[i], err = decodeLibraryEntry(&rs[i], doc); err != nil {
+			return nil, resp, err
+		}
+	}
+	return entries, resp, nil
+}
+
+// ListByUser returns a page of the library entries of the user with the
+// given ID.
+func (s *LibraryEntryService) ListByUser(ctx context.Context, userID string, opts *ListOptions) ([]*LibraryEntry, *Response, error) {
+	return s.List(ctx, withFilter(opts, "userId", userID))
+}
+
+// Create adds the media, an anime or a manga such as
+// Identifier{ID: "3", Type: "manga"}, to the library of the user with the
+// given ID and returns the new library entry. Requires authentication.
+func (s *LibraryEntryService) Create(ctx context.Context, userID string, media Identifier, attrs *LibraryEntryAttributes) (*LibraryEntry, *Response, error) {
+	if attrs == nil {
+		attrs = new(LibraryEntryAttributes)
+	}
+	body := &requestDocument{Data: requestResource{
+		Type:       "libraryEntries",
+		Attributes: attrs,
+		Relationships: map[string]requestRelationship{
+			"user":  {Data: Identifier{ID: userID, Type: "users"}},
+			"media": {Data: media},
+		},
+	}}
+	return s.write(ctx, "POST", "library-entries", body)
+}
+
+// Update changes the non-nil attributes of the library entry with the given
+// ID and returns the updated entry. Requires authentication.
+func (s *LibraryEntryService) Update(ctx context.Context, id string, attrs *LibraryEntryAttributes) (*LibraryEntry, *Response, error) {
+	if attrs == nil {
+		attrs = new(LibraryEntryAttributes)
+	}
+	body := &requestDocument{Data: requestResource{ID: id, Type: "libraryEntries", Attributes: attrs}}
+	return s.write(ctx, "PATCH", "library-entries/"+url.PathEscape(id), body)
+}
+
+// Delete removes the library entry with the given ID. Requires
+// authentication.
+func (s *LibraryEntryService) Delete(ctx context.Context, id string) (*Response, error) {
+	req, err := s.client.NewRequest("DELETE", "library-entries/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.Do(ctx, req, nil)
+}
+
+// write sends body with the given method and decodes the library entry of
+// the response.
+func (s *LibraryEntryService) write(ctx context.Context, method, urlStr string, body *requestDocument) (*LibraryEntry, *Response, error) {
+	req, err := s.client.NewRequest(method, urlStr, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := new(Document)
+	resp, err := s.client.Do(ctx, req, doc)
+	if err != nil {
+		return nil, resp, err
+	}
+	r, err := doc.Resource()
+	if err != nil || r == nil {
+		return nil, resp, err
+	}
+	e, err := decodeLibraryEntry(r, doc)
+	return e, resp, err
+}
+
+// decodeLibraryEntry decodes the library entry resource r of doc.
+func decodeLibraryEntry(r *Resource, doc *Document) (*LibraryEntry, error) {
+	if r.Type != "libraryEntries" {
+		return nil, fmt.Errorf("v2: resource %v %v is not a library entry", r.Type, r.ID)
+	}
+	e := &LibraryEntry{ID: r.ID}
+	if err := r.Decode(e); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"media", "anime", "manga", "user"} {
+		for _, id := range r.Related(name) {
+			rr := doc.Find(id)
+			if rr == nil {
+				continue
+			}
+			var err error
+			switch rr.Type {
+			case "anime":
+				e.Anime, err = decodeAnime(rr, doc)
+			case "manga":
+				e.Manga, err = decodeManga(rr, doc)
+			case "users":
+				e.User, err = decodeUser(rr)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,142 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func TestLibraryEntryService_ListByUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/library-entries", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testQuery(t, r, url.Values{"filter[userId]": {"7"}, "include": {"media,user"}})
+		fmt.Fprint(w, `{
+			"data": [
+				{
+					"id": "100",
+					"type": "libraryEntries",
+					"attributes": {"status": "current", "progress": 5, "rating": "4.5", "reconsuming": true},
+					"relationships": {
+						"media": {"data": {"id": "1", "type": "anime"}},
+						"user": {"data": {"id": "7", "type": "users"}}
+					}
+				},
+				{
+					"id": "101",
+					"type": "libraryEntries",
+					"attributes": {"status": "planned", "ratingTwenty": 14},
+					"relationships": {
+						"media": {"data": {"id": "3", "type": "manga"}},
+						"user": {"data": {"id": "7", "type": "users"}}
+					}
+				}
+			],
+			"included": [
+				{"id": "1", "type": "anime", "attributes": {"slug": "cowboy-bebop"}},
+				{"id": "3", "type": "manga", "attributes": {"slug": "berserk"}},
+				{"id": "7", "type": "users", "attributes": {"name": "cybrox"}}
+			]
+		}`)
+	})
+
+	entries, _, err := client.LibraryEntries.ListByUser(context.Background(), "7", &ListOptions{Include: []string{"media", "user"}})
+	if err != nil {
+		t.Fatalf("LibraryEntries.ListByUser returned error %v", err)
+	}
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("LibraryEntries.ListByUser returned %v entries, want %v", got, want)
+	}
+
+	e := entries[0]
+	if e.ID != "100" || e.Status != StatusCurrent || e.Progress != 5 || !e.Reconsuming {
+		t.Errorf("LibraryEntries.ListByUser first entry is %+v", e)
+	}
+	if e.Rating == nil || *e.Rating != 4.5 {
+		t.Errorf("LibraryEntries.ListByUser first entry rating is %v, want 4.5", e.Rating)
+	}
+	if e.Anime == nil || e.Anime.Slug != "cowboy-bebop" {
+		t.Errorf("LibraryEntries.ListByUser first entry anime is %+v, want cowboy-bebop", e.Anime)
+	}
+	if e.User == nil || e.User.Name != "cybrox" {
+		t.Errorf("LibraryEntries.ListByUser first entry user is %+v, want cybrox", e.User)
+	}
+
+	e = entries[1]
+	if e.Anime != nil || e.Manga == nil || e.Manga.Slug != "berserk" {
+		t.Errorf("LibraryEntries.ListByUser second entry media is anime %+v, manga %+v, want manga berserk", e.Anime, e.Manga)
+	}
+	if got, want := e.RatingTwenty, 14; got != want {
+		t.Errorf("LibraryEntries.ListByUser second entry ratingTwenty is %v, want %v", got, want)
+	}
+}
+
+func TestLibraryEntryService_Create_manga(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/library-entries", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		if got, want := r.Header.Get("Content-Type"), mediaType; got != want {
+			t.Errorf("Request Content-Type is %v, want %v", got, want)
+		}
+		testBody(t, r, `{"data":{"type":"libraryEntries","attributes":{"status":"current","progress":0},`+
+			`"relationships":{"media":{"data":{"id":"3","type":"manga"}},"user":{"data":{"id":"7","type":"users"}}}}}`+"\n")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"data":{"id":"101","type":"libraryEntries","attributes":{"status":"current"}}}`)
+	})
+
+	attrs := &LibraryEntryAttributes{Status: hb.String(StatusCurrent), Progress: hb.Int(0)}
+	e, _, err := client.LibraryEntries.Create(context.Background(), "7", Identifier{ID: "3", Type: "manga"}, attrs)
+	if err != nil {
+		t.Fatalf("LibraryEntries.Create returned error %v", err)
+	}
+	if e.ID != "101" || e.Status != StatusCurrent {
+		t.Errorf("LibraryEntries.Create returned %+v, want entry 101 current", e)
+	}
+}
+
+func TestLibraryEntryService_Update(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/library-entries/101", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		// Zero values that are set are sent.
+		testBody(t, r, `{"data":{"id":"101","type":"libraryEntries","attributes":{"progress":12,"reconsuming":false,"notes":""}}}`+"\n")
+		fmt.Fprint(w, `{"data":{"id":"101","type":"libraryEntries","attributes":{"status":"current","progress":12}}}`)
+	})
+
+	attrs := &LibraryEntryAttributes{Progress: hb.Int(12), Reconsuming: hb.Bool(false), Notes: hb.String("")}
+	e, _, err := client.LibraryEntries.Update(context.Background(), "101", attrs)
+	if err != nil {
+		t.Fatalf("LibraryEntries.Update returned error %v", err)
+	}
+	if got, want := e.Progress, 12; got != want {
+		t.Errorf("LibraryEntries.Update progress is %v, want %v", got, want)
+	}
+}
+
+func TestLibraryEntryService_Delete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/library-entries/101", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := client.LibraryEntries.Delete(context.Background(), "101")
+	if err != nil {
+		t.Fatalf("LibraryEntries.Delete returned error %v", err)
+	}
+	if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+		t.Errorf("LibraryEntries.Delete status is %v, want %v", got, want)
+	}
+}
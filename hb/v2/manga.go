@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// Manga is a manga resource.
+type Manga struct {
+	ID                string            `json:"-"`
+	Slug              string            `json:"slug,omitempty"`
+	Synopsis          string            `json:"synopsis,omitempty"`
+	Titles            map[string]string `json:"titles,omitempty"`
+	CanonicalTitle    string            `json:"canonicalTitle,omitempty"`
+	AbbreviatedTitles []string          `json:"abbreviatedTitles,omitempty"`
+	AverageRating     float64           `json:"averageRating,omitempty"`
+	StartDate         *hb.Date          `json:"startDate,omitempty"`
+	EndDate           *hb.Date          `json:"endDate,omitempty"`
+	PosterImage       *Image            `json:"posterImage,omitempty"`
+	CoverImage        *Image            `json:"coverImage,omitempty"`
+	ChapterCount      int               `json:"chapterCount,omitempty"`
+	VolumeCount       int               `json:"volumeCount,omitempty"`
+	MangaType         string            `json:"mangaType,omitempty"`
+	Serialization     string            `json:"serialization,omitempty"`
+	Status            string            `json:"status,omitempty"`
+	AgeRating         string            `json:"ageRating,omitempty"`
+
+	// Genres are the genres of the manga, which are only set if the
+	// "genres" relationship was included.
+	Genres []*Genre `json:"-"`
+}
+
+// MangaService handles communication with the manga resources of the API.
+type MangaService struct {
+	client *Client
+}
+
+// Get returns the manga with the given ID.
+func (s *MangaService) Get(ctx context.Context, id string, opts *ListOptions) (*Manga, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("manga/"+url.PathEscape(id), opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	r, err := doc.Resource()
+	if err != nil || r == nil {
+		return nil, resp, err
+	}
+	m, err := decodeManga(r, doc)
+	return m, resp, err
+}
+
+// List returns a page of manga.
+func (s *MangaService) List(ctx context.Context, opts *ListOptions) ([]*Manga, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("manga", opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	rs, err := doc.Resources()
+	if err != nil {
+		return nil, resp, err
+	}
+	manga := make([]*Manga, len(rs))
+	for i := range rs {
+		if manga[i], err = decodeManga(&rs[i], doc); err != nil {
+			return nil, resp, err
+		}
+	}
+	return manga, resp, nil
+}
+
+// Search returns a page of the manga that match text.
+func (s *MangaService) Search(ctx context.Context, text string, opts *ListOptions) ([]*Manga, *Response, error) {
+	return s.List(ctx, withFilter(opts, "text", text))
+}
+
+// decodeManga decodes the manga resource r of doc.
+func decodeManga(r *Resource, doc *Document) (*Manga, error) {
+	if r.Type != "manga" {
+		return nil, fmt.Errorf("v2: resource %v %v is not manga", r.Type, r.ID)
+	}
+	m := &Manga{ID: r.ID}
+	if err := r.Decode(m); err != nil {
+		return nil, err
+	}
+	genres, err := decodeGenres(r, doc)
+	if err != nil {
+		return nil, err
+	}
+	m.Genres = genres
+	return m, nil
+}
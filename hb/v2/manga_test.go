@@ -0,0 +1,31 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMangaService_Search(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/manga", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testQuery(t, r, url.Values{"filter[text]": {"berserk"}})
+		fmt.Fprint(w, `{"data":[{"id":"3","type":"manga","attributes":{"slug":"berserk","chapterCount":364,"mangaType":"manga"}}]}`)
+	})
+
+	got, _, err := client.Manga.Search(context.Background(), "berserk", nil)
+	if err != nil {
+		t.Fatalf("Manga.Search returned error %v", err)
+	}
+
+	want := []*Manga{{ID: "3", Slug: "berserk", ChapterCount: 364, MangaType: "manga"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Manga.Search returned %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,372 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+	"github.com/nstratos/go-hummingbird/internal/atomicfile"
+)
+
+const defaultTokenURL = "https://hummingbird.me/api/oauth/token"
+
+// expiryDelta is how long before its expiry a token is considered expired, so
+// that it doesn't expire while a request is in flight.
+const expiryDelta = 10 * time.Second
+
+// Token is an OAuth2 token.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// Expiry is when the access token expires. The zero Expiry means that
+	// it never does.
+	Expiry time.Time `json:"expiry"`
+}
+
+// Valid reports whether the token has an access token that has not expired.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// TokenSource returns the tokens that authenticate the requests of a Client.
+// It must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns a valid token.
+	Token(ctx context.Context) (*Token, error)
+}
+
+// tokenInvalidator is implemented by the TokenSources that can drop a token
+// that the API has rejected, so that the next call of Token returns a new
+// one.
+type tokenInvalidator interface {
+	// invalidate drops t if it is still the current token.
+	invalidate(t *Token)
+}
+
+// TokenStore stores tokens so that they are reused between runs of a program
+// and refreshed tokens are not lost. Implement it to keep tokens in a keyring
+// or another secret store.
+type TokenStore interface {
+	// LoadToken returns the stored token, or nil if there is none.
+	LoadToken() (*Token, error)
+
+	// SaveToken stores a token, replacing the stored one.
+	SaveToken(t *Token) error
+}
+
+// FileTokenStore is a TokenStore that keeps the token in a JSON file that only
+// its owner can read.
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore returns a FileTokenStore that keeps the token in the file
+// at path. The file is created when the first token is saved.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// LoadToken returns the token of the file, or nil if the file doesn't exist.
+func (s *FileTokenStore) LoadToken() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := new(Token)
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveToken writes the token to the file. The token is first written to a
+// temporary file and then renamed so that the file is never left partially
+// written.
+func (s *FileTokenStore) SaveToken(t *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	// The file is created with mode 0600.
+	return atomicfile.WriteFile(s.path, b)
+}
+
+// Config is the OAuth2 configuration of an application.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+
+	// TokenURL is the URL of the token endpoint. Defaults to the endpoint of
+	// Hummingbird.
+	TokenURL string
+
+	// HTTPClient is the client that requests tokens. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// PasswordToken exchanges the name or email and the password of a user for a
+// token, using the password grant.
+func (c *Config) PasswordToken(ctx context.Context, username, password string) (*Token, error) {
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	})
+}
+
+// ClientCredentialsToken returns a token that authenticates the application
+// itself, using the client credentials grant.
+func (c *Config) ClientCredentialsToken(ctx context.Context) (*Token, error) {
+	return c.retrieveToken(ctx, url.Values{"grant_type": {"client_credentials"}})
+}
+
+// RefreshToken exchanges a refresh token for a new token.
+func (c *Config) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	t, err := c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The refresh token is kept if the server doesn't issue a new one.
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken
+	}
+	return t, nil
+}
+
+// TokenSource returns a TokenSource that returns t until it expires and then
+// refreshes it with its refresh token. If store is not nil, the first token
+// is loaded from store when t is nil, and refreshed tokens are saved to
+// store.
+func (c *Config) TokenSource(t *Token, store TokenStore) TokenSource {
+	return &refreshTokenSource{conf: c, token: t, store: store, loaded: t != nil || store == nil}
+}
+
+// ClientCredentialsTokenSource returns a TokenSource that requests a new token
+// with the client credentials grant whenever the previous one expires.
+func (c *Config) ClientCredentialsTokenSource() TokenSource {
+	return &clientCredentialsTokenSource{conf: c}
+}
+
+// tokenJSON is the response of the token endpoint.
+type tokenJSON struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// retrieveToken requests a token from the token endpoint with the parameters
+// of a grant.
+func (c *Config) retrieveToken(ctx context.Context, v url.Values) (*Token, error) {
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	if c.ClientID != "" {
+		v.Set("client_id", c.ClientID)
+	}
+	if c.ClientSecret != "" {
+		v.Set("client_secret", c.ClientSecret)
+	}
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if c := resp.StatusCode; c < 200 || c > 299 {
+		tokenErr := &TokenError{Response: resp, Body: body}
+		json.Unmarshal(body, tokenErr)
+		return nil, tokenErr
+	}
+
+	var tj tokenJSON
+	if err := json.Unmarshal(body, &tj); err != nil {
+		return nil, fmt.Errorf("v2: cannot decode token: %v", err)
+	}
+	if tj.AccessToken == "" {
+		return nil, fmt.Errorf("v2: token response has no access token")
+	}
+	t := &Token{AccessToken: tj.AccessToken, TokenType: tj.TokenType, RefreshToken: tj.RefreshToken}
+	if tj.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(tj.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// TokenError is an error response of the token endpoint. Errors of invalid
+// credentials and refresh tokens can be compared with hb.ErrUnauthorized using
+// errors.Is.
+type TokenError struct {
+	Response    *http.Response
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+
+	// Body is the raw body of the response.
+	Body []byte `json:"-"`
+}
+
+func (e *TokenError) Error() string {
+	msg := e.Code
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	if msg == "" {
+		msg = strings.TrimSpace(string(e.Body))
+	}
+	return fmt.Sprintf("v2: cannot retrieve token: %v %v", e.Response.StatusCode, msg)
+}
+
+// Unwrap returns the error of package hb that matches the error, or nil if
+// none does.
+func (e *TokenError) Unwrap() error {
+	switch c := e.Response.StatusCode; {
+	case e.Code == "invalid_grant" || e.Code == "invalid_client" || c == http.StatusUnauthorized:
+		return hb.ErrUnauthorized
+	case c == http.StatusTooManyRequests:
+		return hb.ErrRateLimited
+	case 500 <= c && c <= 599:
+		return hb.ErrServer
+	}
+	return nil
+}
+
+// refreshTokenSource is the TokenSource of Config.TokenSource.
+type refreshTokenSource struct {
+	conf  *Config
+	store TokenStore
+
+	mu     sync.Mutex
+	token  *Token
+	loaded bool
+}
+
+func (s *refreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loaded {
+		t, err := s.store.LoadToken()
+		if err != nil {
+			return nil, err
+		}
+		s.token, s.loaded = t, true
+	}
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	if s.token == nil || s.token.RefreshToken == "" {
+		return nil, fmt.Errorf("v2: token expired and cannot be refreshed: %w", hb.ErrUnauthorized)
+	}
+	t, err := s.conf.RefreshToken(ctx, s.token.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	s.token = t
+	if s.store != nil {
+		if err := s.store.SaveToken(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (s *refreshTokenSource) invalidate(t *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == t && t != nil {
+		// Only the refresh token is kept so that the next token is
+		// refreshed.
+		s.token = &Token{RefreshToken: t.RefreshToken}
+	}
+}
+
+// clientCredentialsTokenSource is the TokenSource of
+// Config.ClientCredentialsTokenSource.
+type clientCredentialsTokenSource struct {
+	conf *Config
+
+	mu    sync.Mutex
+	token *Token
+}
+
+func (s *clientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	t, err := s.conf.ClientCredentialsToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = t
+	return t, nil
+}
+
+func (s *clientCredentialsTokenSource) invalidate(t *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == t {
+		s.token = nil
+	}
+}
+
+// StaticTokenSource returns a TokenSource that always returns t, which is
+// never refreshed. Its Token method returns an error matching
+// hb.ErrUnauthorized if t is nil or has expired.
+func StaticTokenSource(t *Token) TokenSource {
+	return staticTokenSource{t}
+}
+
+type staticTokenSource struct {
+	t *Token
+}
+
+func (s staticTokenSource) Token(ctx context.Context) (*Token, error) {
+	if !s.t.Valid() {
+		return nil, fmt.Errorf("v2: static token is missing or expired: %w", hb.ErrUnauthorized)
+	}
+	return s.t, nil
+}
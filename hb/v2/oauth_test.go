@@ -0,0 +1,336 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func TestToken_Valid(t *testing.T) {
+	tests := []struct {
+		tok  *Token
+		want bool
+	}{
+		{nil, false},
+		{&Token{}, false},
+		{&Token{AccessToken: "a"}, true},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, true},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(time.Second)}, false},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(-time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.tok.Valid(); got != tt.want {
+			t.Errorf("%+v.Valid() is %v, want %v", tt.tok, got, tt.want)
+		}
+	}
+}
+
+// testConfig returns a Config that requests tokens from the test server.
+func testConfig() *Config {
+	return &Config{ClientID: "id", ClientSecret: "secret", TokenURL: server.URL + "/api/oauth/token"}
+}
+
+func TestConfig_PasswordToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		r.ParseForm()
+		want := map[string]string{"grant_type": "password", "username": "cybrox", "password": "pass", "client_id": "id", "client_secret": "secret"}
+		for k, v := range want {
+			if got := r.PostForm.Get(k); got != v {
+				t.Errorf("Token request %v is %v, want %v", k, got, v)
+			}
+		}
+		fmt.Fprint(w, `{"access_token":"access","token_type":"bearer","refresh_token":"refresh","expires_in":3600,"scope":"public","created_at":1460000000}`)
+	})
+
+	before := time.Now()
+	tok, err := testConfig().PasswordToken(context.Background(), "cybrox", "pass")
+	if err != nil {
+		t.Fatalf("Config.PasswordToken returned error %v", err)
+	}
+
+	if tok.AccessToken != "access" || tok.TokenType != "bearer" || tok.RefreshToken != "refresh" {
+		t.Errorf("Config.PasswordToken returned %+v", tok)
+	}
+	if min := before.Add(time.Hour); tok.Expiry.Before(min) {
+		t.Errorf("Config.PasswordToken expiry is %v, want after %v", tok.Expiry, min)
+	}
+}
+
+func TestConfig_PasswordToken_invalidGrant(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_grant","error_description":"The provided authorization grant is invalid."}`)
+	})
+
+	_, err := testConfig().PasswordToken(context.Background(), "cybrox", "wrong")
+
+	var tokErr *TokenError
+	if !errors.As(err, &tokErr) {
+		t.Fatalf("Config.PasswordToken returned error %v, want *TokenError", err)
+	}
+	if got, want := tokErr.Code, "invalid_grant"; got != want {
+		t.Errorf("TokenError.Code is %v, want %v", got, want)
+	}
+	if !errors.Is(err, hb.ErrUnauthorized) {
+		t.Errorf("Config.PasswordToken error %v does not match hb.ErrUnauthorized", err)
+	}
+}
+
+func TestConfig_RefreshToken_keepsRefreshToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got, want := r.PostForm.Get("refresh_token"), "refresh"; got != want {
+			t.Errorf("Token request refresh_token is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, `{"access_token":"new","token_type":"bearer"}`)
+	})
+
+	tok, err := testConfig().RefreshToken(context.Background(), "refresh")
+	if err != nil {
+		t.Fatalf("Config.RefreshToken returned error %v", err)
+	}
+
+	want := &Token{AccessToken: "new", TokenType: "bearer", RefreshToken: "refresh"}
+	if !reflect.DeepEqual(tok, want) {
+		t.Errorf("Config.RefreshToken returned %+v, want %+v", tok, want)
+	}
+}
+
+func TestClient_TokenSource_refresh(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	refreshes := 0
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got, want := r.PostForm.Get("grant_type"), "refresh_token"; got != want {
+			t.Errorf("Token request grant_type is %v, want %v", got, want)
+		}
+		mu.Lock()
+		refreshes++
+		mu.Unlock()
+		fmt.Fprint(w, `{"access_token":"new","token_type":"bearer","refresh_token":"refresh2","expires_in":3600}`)
+	})
+	mux.HandleFunc("/api/v2/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer new"; got != want {
+			t.Errorf("Request Authorization header is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, `{"data":{"id":"1","type":"anime"}}`)
+	})
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	expired := &Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}
+	if err := store.SaveToken(expired); err != nil {
+		t.Fatalf("FileTokenStore.SaveToken returned error %v", err)
+	}
+	client.TokenSource = testConfig().TokenSource(nil, store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.Anime.Get(context.Background(), "1", nil); err != nil {
+				t.Errorf("Anime.Get returned error %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := refreshes, 1; got != want {
+		t.Errorf("Token was refreshed %v times, want %v", got, want)
+	}
+	saved, err := store.LoadToken()
+	if err != nil {
+		t.Fatalf("FileTokenStore.LoadToken returned error %v", err)
+	}
+	if saved == nil || saved.AccessToken != "new" || saved.RefreshToken != "refresh2" {
+		t.Errorf("FileTokenStore saved token is %+v, want the refreshed token", saved)
+	}
+}
+
+func TestConfig_TokenSource_cannotRefresh(t *testing.T) {
+	ts := (&Config{}).TokenSource(&Token{AccessToken: "old", Expiry: time.Now().Add(-time.Minute)}, nil)
+
+	_, err := ts.Token(context.Background())
+
+	if !errors.Is(err, hb.ErrUnauthorized) {
+		t.Errorf("TokenSource.Token error %v does not match hb.ErrUnauthorized", err)
+	}
+}
+
+func TestConfig_ClientCredentialsTokenSource(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got, want := r.PostForm.Get("grant_type"), "client_credentials"; got != want {
+			t.Errorf("Token request grant_type is %v, want %v", got, want)
+		}
+		requests++
+		// The first token expires immediately.
+		expiresIn := 3600
+		if requests == 1 {
+			expiresIn = 1
+		}
+		fmt.Fprintf(w, `{"access_token":"token%d","token_type":"bearer","expires_in":%d}`, requests, expiresIn)
+	})
+
+	ts := testConfig().ClientCredentialsTokenSource()
+	for _, want := range []string{"token1", "token2", "token2"} {
+		tok, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatalf("TokenSource.Token returned error %v", err)
+		}
+		if got := tok.AccessToken; got != want {
+			t.Errorf("TokenSource.Token access token is %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFileTokenStore_LoadToken_missing(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+
+	tok, err := store.LoadToken()
+
+	if err != nil || tok != nil {
+		t.Errorf("FileTokenStore.LoadToken of missing file returned %+v, %v, want nil, nil", tok, err)
+	}
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/users/1", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer static"; got != want {
+			t.Errorf("Request Authorization header is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, `{"data":{"id":"1","type":"users"}}`)
+	})
+
+	client.TokenSource = StaticTokenSource(&Token{AccessToken: "static"})
+	if _, _, err := client.Users.Get(context.Background(), "1", nil); err != nil {
+		t.Errorf("Users.Get returned error %v", err)
+	}
+}
+
+func TestStaticTokenSource_invalid(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/users/1", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request sent without a valid token.")
+	})
+
+	for _, tok := range []*Token{nil, {}, {AccessToken: "a", Expiry: time.Now().Add(-time.Hour)}} {
+		client.TokenSource = StaticTokenSource(tok)
+		_, _, err := client.Users.Get(context.Background(), "1", nil)
+		if !errors.Is(err, hb.ErrUnauthorized) {
+			t.Errorf("Users.Get with token %+v returned error %v, want %v", tok, err, hb.ErrUnauthorized)
+		}
+	}
+}
+
+// nilTokenSource is a TokenSource that returns no token and no error.
+type nilTokenSource struct{}
+
+func (nilTokenSource) Token(ctx context.Context) (*Token, error) { return nil, nil }
+
+func TestClient_Do_nilToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.TokenSource = nilTokenSource{}
+	_, _, err := client.Users.Get(context.Background(), "1", nil)
+	if !errors.Is(err, hb.ErrUnauthorized) {
+		t.Errorf("Users.Get returned error %v, want %v", err, hb.ErrUnauthorized)
+	}
+}
+
+func TestClient_TokenSource_rejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	refreshes := 0
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		fmt.Fprint(w, `{"access_token":"new","token_type":"bearer","refresh_token":"refresh2","expires_in":3600}`)
+	})
+	var auths []string
+	mux.HandleFunc("/api/v2/library-entries", func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := string(b), `{"data":{"type":"libraryEntries"}}`+"\n"; got != want {
+			t.Errorf("Request body is %v, want %v", got, want)
+		}
+		if r.Header.Get("Authorization") != "Bearer new" {
+			http.Error(w, `{"errors":[{"title":"Invalid token","status":"401"}]}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"id":"1","type":"libraryEntries"}}`)
+	})
+
+	// The token has not expired but the API rejects it.
+	revoked := &Token{AccessToken: "revoked", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	client.TokenSource = testConfig().TokenSource(revoked, nil)
+
+	req, err := client.NewRequest("POST", "library-entries", map[string]interface{}{"data": map[string]string{"type": "libraryEntries"}})
+	if err != nil {
+		t.Fatalf("NewRequest returned error %v", err)
+	}
+	if _, err := client.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("Do returned error %v", err)
+	}
+	if got, want := auths, []string{"Bearer revoked", "Bearer new"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Request Authorization headers are %v, want %v", got, want)
+	}
+	if got, want := refreshes, 1; got != want {
+		t.Errorf("Token was refreshed %v times, want %v", got, want)
+	}
+}
+
+func TestClient_TokenSource_rejectedTwice(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token":"new","token_type":"bearer","expires_in":3600}`)
+	})
+	requests := 0
+	mux.HandleFunc("/api/v2/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, `{"errors":[{"title":"Invalid token","status":"401"}]}`, http.StatusUnauthorized)
+	})
+
+	client.TokenSource = testConfig().ClientCredentialsTokenSource()
+	_, _, err := client.Anime.Get(context.Background(), "1", nil)
+	if !errors.Is(err, hb.ErrUnauthorized) {
+		t.Errorf("Anime.Get returned error %v, want %v", err, hb.ErrUnauthorized)
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("Request was sent %v times, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+// User is a user resource.
+type User struct {
+	ID                      string     `json:"-"`
+	Name                    string     `json:"name,omitempty"`
+	About                   string     `json:"about,omitempty"`
+	Bio                     string     `json:"bio,omitempty"`
+	Location                string     `json:"location,omitempty"`
+	Website                 string     `json:"website,omitempty"`
+	WaifuOrHusbando         string     `json:"waifuOrHusbando,omitempty"`
+	LifeSpentOnAnime        int        `json:"lifeSpentOnAnime,omitempty"`
+	TitleLanguagePreference string     `json:"titleLanguagePreference,omitempty"`
+	Avatar                  *Image     `json:"avatar,omitempty"`
+	CoverImage              *Image     `json:"coverImage,omitempty"`
+	FollowersCount          int        `json:"followersCount,omitempty"`
+	FollowingCount          int        `json:"followingCount,omitempty"`
+	CreatedAt               *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt               *time.Time `json:"updatedAt,omitempty"`
+}
+
+// UserService handles communication with the user resources of the API.
+type UserService struct {
+	client *Client
+}
+
+// Get returns the user with the given ID.
+func (s *UserService) Get(ctx context.Context, id string, opts *ListOptions) (*User, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("users/"+url.PathEscape(id), opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	r, err := doc.Resource()
+	if err != nil || r == nil {
+		return nil, resp, err
+	}
+	u, err := decodeUser(r)
+	return u, resp, err
+}
+
+// GetByName returns the user with the given name. The error wraps
+// hb.ErrNotFound if there is no such user.
+func (s *UserService) GetByName(ctx context.Context, name string, opts *ListOptions) (*User, *Response, error) {
+	users, resp, err := s.List(ctx, withFilter(opts, "name", name))
+	if err != nil {
+		return nil, resp, err
+	}
+	if len(users) == 0 {
+		return nil, resp, fmt.Errorf("v2: user %q: %w", name, hb.ErrNotFound)
+	}
+	return users[0], resp, nil
+}
+
+// List returns a page of users.
+func (s *UserService) List(ctx context.Context, opts *ListOptions) ([]*User, *Response, error) {
+	doc, resp, err := s.client.get(ctx, resourceURL("users", opts))
+	if err != nil {
+		return nil, resp, err
+	}
+	rs, err := doc.Resources()
+	if err != nil {
+		return nil, resp, err
+	}
+	users := make([]*User, len(rs))
+	for i := range rs {
+		if users[i], err = decodeUser(&rs[i]); err != nil {
+			return nil, resp, err
+		}
+	}
+	return users, resp, nil
+}
+
+// decodeUser decodes the user resource r.
+func decodeUser(r *Resource) (*User, error) {
+	if r.Type != "users" {
+		return nil, fmt.Errorf("v2: resource %v %v is not a user", r.Type, r.ID)
+	}
+	u := &User{ID: r.ID}
+	if err := r.Decode(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
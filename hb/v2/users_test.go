@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+func TestUserService_GetByName(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/users", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testQuery(t, r, url.Values{"filter[name]": {"cybrox"}})
+		fmt.Fprint(w, `{"data":[{"id":"7","type":"users","attributes":{"name":"cybrox","lifeSpentOnAnime":3200,"avatar":{"original":"https://example.com/a.png"}}}]}`)
+	})
+
+	got, _, err := client.Users.GetByName(context.Background(), "cybrox", nil)
+	if err != nil {
+		t.Fatalf("Users.GetByName returned error %v", err)
+	}
+
+	want := &User{ID: "7", Name: "cybrox", LifeSpentOnAnime: 3200, Avatar: &Image{Original: "https://example.com/a.png"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Users.GetByName returned %+v, want %+v", got, want)
+	}
+}
+
+func TestUserService_GetByName_notFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	_, _, err := client.Users.GetByName(context.Background(), "nobody", nil)
+
+	if !errors.Is(err, hb.ErrNotFound) {
+		t.Errorf("Users.GetByName error %v does not match hb.ErrNotFound", err)
+	}
+}
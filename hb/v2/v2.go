@@ -0,0 +1,379 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+const (
+	defaultBaseURL = "https://hummingbird.me/api/v2/"
+
+	// mediaType is the media type of JSON:API documents.
+	mediaType = "application/vnd.api+json"
+)
+
+// Client manages communication with version 2 of the Hummingbird API.
+type Client struct {
+	client *http.Client
+
+	// BaseURL is the URL that the paths of the resources are resolved
+	// against. It must have a trailing slash.
+	BaseURL *url.URL
+
+	// RateLimiter, if set, limits the rate of the requests sent by all the
+	// services of the Client.
+	RateLimiter *hb.RateLimiter
+
+	// TokenSource, if set, provides the OAuth2 token that authenticates the
+	// requests. Expired tokens are refreshed by the TokenSource before the
+	// requests are sent. The tokens of Config.TokenSource and
+	// Config.ClientCredentialsTokenSource are also refreshed when the API
+	// rejects them, see Do.
+	TokenSource TokenSource
+
+	Anime          *AnimeService
+	Manga          *MangaService
+	Users          *UserService
+	LibraryEntries *LibraryEntryService
+	Episodes       *EpisodeService
+}
+
+// NewClient returns a new client for version 2 of the Hummingbird API. If
+// httpClient is nil, http.DefaultClient is used.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	baseURL, _ := url.Parse(defaultBaseURL)
+
+	c := &Client{client: httpClient, BaseURL: baseURL}
+	c.Anime = &AnimeService{client: c}
+	c.Manga = &MangaService{client: c}
+	c.Users = &UserService{client: c}
+	c.LibraryEntries = &LibraryEntryService{client: c}
+	c.Episodes = &EpisodeService{client: c}
+	return c
+}
+
+// ListOptions are the query parameters of JSON:API requests.
+type ListOptions struct {
+	// Fields are the sparse fieldsets: the attributes and relationships to
+	// return for each type of resource, such as
+	// {"anime": {"slug", "canonicalTitle"}}.
+	Fields map[string][]string
+
+	// Filter filters the resources of a list, such as {"text": "bebop"}.
+	Filter map[string]string
+
+	// Include are the relationships whose resources are included in the
+	// response, such as {"anime", "user"}.
+	Include []string
+
+	// Sort are the attributes to sort a list by. Attributes starting with a
+	// "-" are sorted in descending order.
+	Sort []string
+
+	// PageLimit and PageOffset select a page of a list.
+	PageLimit  int
+	PageOffset int
+
+	// Link, if set, is a link returned by the API, such as Response.Next,
+	// that the request is sent to as it is. The other options are ignored.
+	// If the Client has a TokenSource, links to a host other than the one
+	// of BaseURL are refused.
+	Link string
+}
+
+// values returns the options as query parameters.
+func (o *ListOptions) values() url.Values {
+	v := url.Values{}
+	if o == nil {
+		return v
+	}
+	for typ, fields := range o.Fields {
+		v.Set("fields["+typ+"]", strings.Join(fields, ","))
+	}
+	for name, value := range o.Filter {
+		v.Set("filter["+name+"]", value)
+	}
+	if len(o.Include) > 0 {
+		v.Set("include", strings.Join(o.Include, ","))
+	}
+	if len(o.Sort) > 0 {
+		v.Set("sort", strings.Join(o.Sort, ","))
+	}
+	if o.PageLimit > 0 {
+		v.Set("page[limit]", strconv.Itoa(o.PageLimit))
+	}
+	if o.PageOffset > 0 {
+		v.Set("page[offset]", strconv.Itoa(o.PageOffset))
+	}
+	return v
+}
+
+// resourceURL returns the URL of path with the query parameters of opts, or
+// the link of opts if it has one.
+func resourceURL(path string, opts *ListOptions) string {
+	if opts != nil && opts.Link != "" {
+		return opts.Link
+	}
+	q := opts.values()
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
+
+// NewRequest creates an API request. A relative URL can be provided in urlStr,
+// in which case it is resolved relative to the BaseURL of the Client. If body
+// is not nil, it is encoded to JSON and used as the request body.
+func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	rel, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	u := c.BaseURL.ResolveReference(rel)
+
+	var buf io.ReadWriter
+	if body != nil {
+		buf = new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			return nil, fmt.Errorf("cannot encode body: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", mediaType)
+	if body != nil {
+		req.Header.Set("Content-Type", mediaType)
+	}
+	return req, nil
+}
+
+// Response is an API response. It holds the links and the meta information of
+// the JSON:API document.
+type Response struct {
+	*http.Response
+
+	// Links are the top level links of the document.
+	Links Links
+
+	// Next, Prev, First and Last are the pagination links of a list, empty
+	// if there is no such page.
+	Next, Prev, First, Last string
+
+	// Meta is the meta information of the document, such as the count of
+	// the resources of a list.
+	Meta map[string]interface{}
+}
+
+// Do sends an API request bound to ctx and decodes the JSON:API document of
+// the response into doc, if it is not nil. If an API error occurs, both the
+// response and an *ErrorResponse are returned.
+//
+// If the API rejects a token of the TokenSource that has not expired, such as
+// a revoked one, and the TokenSource can refresh it, the token is refreshed
+// and the request is sent once more.
+func (c *Client) Do(ctx context.Context, req *http.Request, doc *Document) (*Response, error) {
+	resp, t, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if inv, ok := c.TokenSource.(tokenInvalidator); ok && resp.StatusCode == http.StatusUnauthorized && (req.Body == nil || req.GetBody != nil) {
+		r := req
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		inv.invalidate(t)
+		if resp, _, err = c.send(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	response := &Response{Response: resp}
+	if err := checkResponse(resp); err != nil {
+		return response, err
+	}
+	if doc == nil || resp.StatusCode == http.StatusNoContent {
+		return response, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(doc); err != nil && err != io.EOF {
+		return response, err
+	}
+	response.Links, response.Meta = doc.Links, doc.Meta
+	response.Next, response.Prev = doc.Links["next"], doc.Links["prev"]
+	response.First, response.Last = doc.Links["first"], doc.Links["last"]
+	return response, nil
+}
+
+// send sends req bound to ctx, authenticated with a token of the TokenSource
+// of the Client, and returns the response and the token.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, *Token, error) {
+	if c.RateLimiter != nil {
+		if _, err := c.RateLimiter.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	var t *Token
+	if c.TokenSource != nil {
+		// Links, such as Response.Next, come from the API and could point
+		// to another host, which must not receive the token.
+		if !sameOrigin(req.URL, c.BaseURL) {
+			return nil, nil, fmt.Errorf("v2: refusing to send the token to %v://%v, which is not the host of BaseURL", req.URL.Scheme, req.URL.Host)
+		}
+		var err error
+		if t, err = c.TokenSource.Token(ctx); err != nil {
+			return nil, nil, err
+		}
+		if t == nil || t.AccessToken == "" {
+			return nil, nil, fmt.Errorf("v2: token source returned no access token: %w", hb.ErrUnauthorized)
+		}
+		typ := t.TokenType
+		if typ == "" || strings.EqualFold(typ, "bearer") {
+			typ = "Bearer"
+		}
+		req = req.Clone(ctx)
+		req.Header.Set("Authorization", typ+" "+t.AccessToken)
+	}
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		return nil, nil, err
+	}
+	return resp, t, nil
+}
+
+// sameOrigin reports whether u and v have the same scheme and host.
+func sameOrigin(u, v *url.URL) bool {
+	return strings.EqualFold(u.Scheme, v.Scheme) && strings.EqualFold(u.Host, v.Host)
+}
+
+// get fetches the document at urlStr.
+func (c *Client) get(ctx context.Context, urlStr string) (*Document, *Response, error) {
+	req, err := c.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := new(Document)
+	resp, err := c.Do(ctx, req, doc)
+	if err != nil {
+		return nil, resp, err
+	}
+	return doc, resp, nil
+}
+
+// withFilter returns a copy of opts that also filters by name.
+func withFilter(opts *ListOptions, name, value string) *ListOptions {
+	o := ListOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	filter := make(map[string]string, len(o.Filter)+1)
+	for k, v := range o.Filter {
+		filter[k] = v
+	}
+	filter[name] = value
+	o.Filter = filter
+	return &o
+}
+
+// ErrorObject is an error of a JSON:API error document.
+type ErrorObject struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	Source *struct {
+		Pointer   string `json:"pointer,omitempty"`
+		Parameter string `json:"parameter,omitempty"`
+	} `json:"source,omitempty"`
+}
+
+func (e ErrorObject) Error() string {
+	if e.Detail != "" {
+		return e.Title + ": " + e.Detail
+	}
+	return e.Title
+}
+
+// ErrorResponse represents an error response of the API. Like the
+// ErrorResponse of package hb, it can be compared with hb.ErrNotFound,
+// hb.ErrUnauthorized, hb.ErrRateLimited and hb.ErrServer using errors.Is.
+type ErrorResponse struct {
+	Response *http.Response
+	Errors   []ErrorObject `json:"errors"`
+
+	// Body is the raw body of the API response.
+	Body []byte `json:"-"`
+}
+
+func (r *ErrorResponse) Error() string {
+	var msgs []string
+	for _, e := range r.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	if len(msgs) == 0 {
+		msgs = append(msgs, strings.TrimSpace(string(r.Body)))
+	}
+	req := r.Response.Request
+	if req == nil {
+		return fmt.Sprintf("%v %v", r.Response.StatusCode, strings.Join(msgs, "; "))
+	}
+	return fmt.Sprintf("%v %v: %v %v", req.Method, req.URL, r.Response.StatusCode, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of package hb that matches the status code of the
+// response, or nil if none does.
+func (r *ErrorResponse) Unwrap() error {
+	switch c := r.Response.StatusCode; {
+	case c == http.StatusNotFound:
+		return hb.ErrNotFound
+	case c == http.StatusUnauthorized:
+		return hb.ErrUnauthorized
+	case c == http.StatusTooManyRequests:
+		return hb.ErrRateLimited
+	case 500 <= c && c <= 599:
+		return hb.ErrServer
+	}
+	return nil
+}
+
+// checkResponse returns an *ErrorResponse if the status code of r is outside
+// the 200 range.
+func checkResponse(r *http.Response) error {
+	if c := r.StatusCode; 200 <= c && c <= 299 {
+		return nil
+	}
+	errorResponse := &ErrorResponse{Response: r}
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		errorResponse.Body = body
+		json.Unmarshal(body, errorResponse)
+	}
+	return errorResponse
+}
@@ -0,0 +1,248 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/nstratos/go-hummingbird/hb"
+)
+
+var (
+	// client is the v2 client that is being tested.
+	client *Client
+
+	// server is a test HTTP server that is being started on each test with
+	// setup() to provide mock API responses.
+	server *httptest.Server
+
+	// mux is the HTTP request multiplexer that the test HTTP server uses.
+	mux *http.ServeMux
+)
+
+// setup sets up a test HTTP server and a v2.Client configured to use the URL
+// of the test server.
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/api/v2/")
+}
+
+// teardown closes the test HTTP server.
+func teardown() {
+	server.Close()
+}
+
+func testMethod(t *testing.T, r *http.Request, want string) {
+	if got := r.Method; got != want {
+		t.Errorf("Request method: %v, want %v", got, want)
+	}
+}
+
+func testQuery(t *testing.T, r *http.Request, want url.Values) {
+	if got := r.URL.Query(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Request query is %v, want %v", got, want)
+	}
+}
+
+func testBody(t *testing.T, r *http.Request, want string) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Errorf("Unable to read body")
+	}
+	if got := string(b); got != want {
+		t.Errorf("Request body: %v, want %v", got, want)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	c := NewClient(nil)
+
+	if got, want := c.BaseURL.String(), defaultBaseURL; got != want {
+		t.Errorf("NewClient BaseURL is %v, want %v", got, want)
+	}
+}
+
+func TestClient_NewRequest(t *testing.T) {
+	c := NewClient(nil)
+
+	req, _ := c.NewRequest("GET", "anime/1", nil)
+
+	if got, want := req.URL.String(), defaultBaseURL+"anime/1"; got != want {
+		t.Errorf("NewRequest URL is %v, want %v", got, want)
+	}
+	if got, want := req.Header.Get("Accept"), mediaType; got != want {
+		t.Errorf("NewRequest Accept header is %v, want %v", got, want)
+	}
+	if got := req.Header.Get("Content-Type"); got != "" {
+		t.Errorf("NewRequest without body has Content-Type %v", got)
+	}
+}
+
+func TestListOptions_values(t *testing.T) {
+	opts := &ListOptions{
+		Fields:     map[string][]string{"anime": {"slug", "canonicalTitle"}},
+		Filter:     map[string]string{"text": "cowboy bebop"},
+		Include:    []string{"genres", "episodes"},
+		Sort:       []string{"-averageRating", "slug"},
+		PageLimit:  5,
+		PageOffset: 10,
+	}
+
+	want := url.Values{
+		"fields[anime]": {"slug,canonicalTitle"},
+		"filter[text]":  {"cowboy bebop"},
+		"include":       {"genres,episodes"},
+		"sort":          {"-averageRating,slug"},
+		"page[limit]":   {"5"},
+		"page[offset]":  {"10"},
+	}
+	if got := opts.values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListOptions.values is %v, want %v", got, want)
+	}
+}
+
+func TestResourceURL(t *testing.T) {
+	tests := []struct {
+		opts *ListOptions
+		want string
+	}{
+		{nil, "anime"},
+		{&ListOptions{}, "anime"},
+		{&ListOptions{PageLimit: 2}, "anime?page%5Blimit%5D=2"},
+		{&ListOptions{PageLimit: 2, Link: "https://example.com/next"}, "https://example.com/next"},
+	}
+	for _, tt := range tests {
+		if got := resourceURL("anime", tt.opts); got != tt.want {
+			t.Errorf("resourceURL(%+v) is %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	opts := &ListOptions{Filter: map[string]string{"a": "1"}}
+
+	got := withFilter(opts, "b", "2")
+
+	if want := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(got.Filter, want) {
+		t.Errorf("withFilter Filter is %v, want %v", got.Filter, want)
+	}
+	if want := map[string]string{"a": "1"}; !reflect.DeepEqual(opts.Filter, want) {
+		t.Errorf("withFilter changed the filter of opts to %v", opts.Filter)
+	}
+}
+
+func TestClient_Do_pagination(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.URL.Query().Get("page[offset]") == "1" {
+			fmt.Fprint(w, `{"data":[{"id":"2","type":"anime","attributes":{"slug":"b"}}],"links":{"prev":"`+server.URL+`/api/v2/anime?page%5Blimit%5D=1"}}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"data":[{"id":"1","type":"anime","attributes":{"slug":"a"}}],
+			"links":{"next":{"href":"`+server.URL+`/api/v2/anime?page%5Blimit%5D=1&page%5Boffset%5D=1"}},
+			"meta":{"count":2}
+		}`)
+	})
+
+	var slugs []string
+	opts := &ListOptions{PageLimit: 1}
+	for {
+		anime, resp, err := client.Anime.List(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Anime.List returned error %v", err)
+		}
+		for _, a := range anime {
+			slugs = append(slugs, a.Slug)
+		}
+		if resp.Next == "" {
+			if resp.Prev == "" {
+				t.Errorf("Anime.List last page has no prev link")
+			}
+			break
+		}
+		if got, want := resp.Meta["count"], 2.0; got != want {
+			t.Errorf("Anime.List meta count is %v, want %v", got, want)
+		}
+		opts = &ListOptions{Link: resp.Next}
+	}
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("Anime.List pages returned %v, want %v", slugs, want)
+	}
+}
+
+func TestClient_Do_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime/0", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaType)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors":[{"title":"Record not found","detail":"The record identified by 0 could not be found.","status":"404"}]}`)
+	})
+
+	_, _, err := client.Anime.Get(context.Background(), "0", nil)
+
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("Anime.Get returned error %v, want *ErrorResponse", err)
+	}
+	if !errors.Is(err, hb.ErrNotFound) {
+		t.Errorf("Anime.Get error %v does not match hb.ErrNotFound", err)
+	}
+	want := []ErrorObject{{Title: "Record not found", Detail: "The record identified by 0 could not be found.", Status: "404"}}
+	if got := errResp.Errors; !reflect.DeepEqual(got, want) {
+		t.Errorf("ErrorResponse.Errors is %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_Do_errorWithoutDocument(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v2/anime/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	})
+
+	_, _, err := client.Anime.Get(context.Background(), "1", nil)
+
+	if !errors.Is(err, hb.ErrServer) {
+		t.Errorf("Anime.Get error %v does not match hb.ErrServer", err)
+	}
+}
+
+func TestClient_Do_crossHostLink(t *testing.T) {
+	setup()
+	defer teardown()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Request sent to another host with Authorization %q", r.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+
+	mux.HandleFunc("/api/v2/anime", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":[],"links":{"next":"%v/api/v2/anime?page%%5Boffset%%5D=10"}}`, other.URL)
+	})
+
+	client.TokenSource = StaticTokenSource(&Token{AccessToken: "secret"})
+	_, resp, err := client.Anime.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Anime.List returned error %v", err)
+	}
+	if _, _, err := client.Anime.List(context.Background(), &ListOptions{Link: resp.Next}); err == nil {
+		t.Error("Anime.List of a link to another host returned no error")
+	}
+}
@@ -0,0 +1,30 @@
+// Package atomicfile writes files so that readers never see them partially
+// written.
+package atomicfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to the file at path. The data is first written to a
+// temporary file in the same directory, which only its owner can read, and
+// then renamed to path.
+func WriteFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package atomicfile
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	for _, data := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(data)); err != nil {
+			t.Fatalf("WriteFile returned error %v", err)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile returned error %v", err)
+		}
+		if got := string(b); got != data {
+			t.Errorf("File has %q, want %q", got, data)
+		}
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Directory has %d files, want only the written one", len(files))
+	}
+	if mode := files[0].Mode().Perm(); mode != 0600 {
+		t.Errorf("File mode is %v, want 0600", mode)
+	}
+}
+
+func TestWriteFile_missingDir(t *testing.T) {
+	if err := WriteFile(filepath.Join(t.TempDir(), "missing", "file"), nil); err == nil {
+		t.Error("WriteFile to a missing directory returned no error")
+	}
+}